@@ -2,28 +2,49 @@ package config
 
 import (
 	"errors"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server ServerConfig
-	Mongo  MongoConfig
-	AWS    AWSConfig
+	Server  ServerConfig
+	Storage string
+	Mongo   MongoConfig
+	Memory  MemoryConfig
+	AWS     AWSConfig
+	Trash   TrashConfig
+	// Providers configures the social login providers by the name clients
+	// pass as the login type.
+	Providers map[string]ProviderConfig
 }
 
 var DefaultConfig = Config{
-	Server: DefaultServerConfig,
-	Mongo:  DefaultMongoConfig,
+	Server:    DefaultServerConfig,
+	Storage:   "mongo",
+	Mongo:     DefaultMongoConfig,
+	Trash:     DefaultTrashConfig,
+	Providers: DefaultProviders,
 }
 
 type ServerConfig struct {
 	BindAddr string `mapstructure:"bind_addr"`
 	Secret   string
+	// Timezone is the IANA time zone of users who have not set their own.
+	Timezone string
+	// Admins are the IDs of the users who may edit the catalogs.
+	Admins []string
+	// AccessTokenTTL is how long an access token is valid.
+	AccessTokenTTL time.Duration `mapstructure:"access_token_ttl"`
+	// RefreshTokenTTL is how long a session lasts without being refreshed.
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
 }
 
 var DefaultServerConfig = ServerConfig{
-	BindAddr: ":8080",
+	BindAddr:        ":8080",
+	Timezone:        "Asia/Seoul",
+	AccessTokenTTL:  15 * time.Minute,
+	RefreshTokenTTL: 30 * 24 * time.Hour,
 }
 
 type MongoConfig struct {
@@ -36,6 +57,12 @@ var DefaultMongoConfig = MongoConfig{
 	Database: "dailyscoop",
 }
 
+// MemoryConfig seeds the in-memory storage backend, which starts out empty.
+type MemoryConfig struct {
+	Emotions []string
+	Themes   []string
+}
+
 type AWSConfig struct {
 	Bucket          string `mapstructure:"bucket"`
 	Region          string `mapstructure:"region"`
@@ -44,19 +71,121 @@ type AWSConfig struct {
 	URL             string `mapstructure:"url"`
 }
 
+// ProviderConfig configures a social login provider. Which fields are used
+// depends on its type.
+type ProviderConfig struct {
+	// Type selects the implementation of the provider, and defaults to its
+	// name.
+	Type     string
+	ClientID string `mapstructure:"client_id"`
+	// UserInfoURL is where access tokens are exchanged for the user.
+	UserInfoURL string `mapstructure:"user_info_url"`
+	// JWKSURL serves the keys ID tokens are signed with.
+	JWKSURL string `mapstructure:"jwks_url"`
+	// Issuers are the accepted issuers of ID tokens.
+	Issuers []string
+}
+
+// DefaultProviders are the endpoints of the providers we support. Settings in
+// the config file override them field by field.
+var DefaultProviders = map[string]ProviderConfig{
+	"kakao": {
+		UserInfoURL: "https://kapi.kakao.com/v2/user/me",
+	},
+	"google": {
+		JWKSURL: "https://www.googleapis.com/oauth2/v3/certs",
+		Issuers: []string{"accounts.google.com", "https://accounts.google.com"},
+	},
+	"apple": {
+		JWKSURL: "https://appleid.apple.com/auth/keys",
+		Issuers: []string{"https://appleid.apple.com"},
+	},
+}
+
+// TrashConfig controls how long deleted diaries stay in the trash and how
+// often expired ones are purged.
+type TrashConfig struct {
+	Retention     time.Duration `mapstructure:"retention"`
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
+var DefaultTrashConfig = TrashConfig{
+	Retention:     30 * 24 * time.Hour,
+	PurgeInterval: time.Hour,
+}
+
 func LoadConfig() (Config, error) {
 	viper.SetConfigName("dailyscoop")
 	viper.AddConfigPath(".")
+	// Deployments that predate the providers section set the Google client
+	// ID through the environment.
+	if err := viper.BindEnv("providers.google.client_id", "GOOGLE_KEY"); err != nil {
+		return Config{}, err
+	}
 	cfg := DefaultConfig
+	cfg.Providers = nil
 
 	if err := viper.ReadInConfig(); err != nil {
-		if errors.As(err, &viper.ConfigFileNotFoundError{}) {
-			return cfg, nil
+		if !errors.As(err, &viper.ConfigFileNotFoundError{}) {
+			return Config{}, err
 		}
-		return Config{}, err
 	}
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return Config{}, err
 	}
+	cfg.Providers = withDefaultProviders(cfg.Providers)
+	if err := cfg.Server.validate(); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Trash.validate(); err != nil {
+		return Config{}, err
+	}
 	return cfg, nil
 }
+
+func withDefaultProviders(providers map[string]ProviderConfig) map[string]ProviderConfig {
+	merged := make(map[string]ProviderConfig, len(providers)+len(DefaultProviders))
+	for name, pc := range providers {
+		merged[name] = pc
+	}
+	for name, def := range DefaultProviders {
+		pc := merged[name]
+		if pc.Type == "" {
+			pc.Type = def.Type
+		}
+		if pc.ClientID == "" {
+			pc.ClientID = def.ClientID
+		}
+		if pc.UserInfoURL == "" {
+			pc.UserInfoURL = def.UserInfoURL
+		}
+		if pc.JWKSURL == "" {
+			pc.JWKSURL = def.JWKSURL
+		}
+		if len(pc.Issuers) == 0 {
+			pc.Issuers = def.Issuers
+		}
+		merged[name] = pc
+	}
+	return merged
+}
+
+func (sc ServerConfig) validate() error {
+	if sc.AccessTokenTTL <= 0 {
+		return errors.New("config: server.access_token_ttl must be positive")
+	}
+	if sc.RefreshTokenTTL <= 0 {
+		return errors.New("config: server.refresh_token_ttl must be positive")
+	}
+	return nil
+}
+
+func (tc TrashConfig) validate() error {
+	if tc.Retention <= 0 {
+		return errors.New("config: trash.retention must be positive")
+	}
+	if tc.PurgeInterval <= 0 {
+		return errors.New("config: trash.purge_interval must be positive")
+	}
+	return nil
+}
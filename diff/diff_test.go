@@ -0,0 +1,41 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func eq(text string) Line  { return Line{Op: OpEqual, Text: text} }
+func ins(text string) Line { return Line{Op: OpInsert, Text: text} }
+func del(text string) Line { return Line{Op: OpDelete, Text: text} }
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Line
+	}{
+		{"both empty", "", "", nil},
+		{"from empty", "", "a\nb", []Line{ins("a"), ins("b")}},
+		{"to empty", "a\nb", "", []Line{del("a"), del("b")}},
+		{"equal", "a\nb", "a\nb", []Line{eq("a"), eq("b")}},
+		{"crlf equals lf", "a\r\nb", "a\nb", []Line{eq("a"), eq("b")}},
+		{"crlf change", "a\r\nb\r\nc", "a\nx\nc", []Line{eq("a"), del("b"), ins("x"), eq("c")}},
+		{"insert at start", "b\nc", "a\nb\nc", []Line{ins("a"), eq("b"), eq("c")}},
+		{"insert in middle", "a\nc", "a\nb\nc", []Line{eq("a"), ins("b"), eq("c")}},
+		{"insert at end", "a\nb", "a\nb\nc", []Line{eq("a"), eq("b"), ins("c")}},
+		{"delete at start", "a\nb\nc", "b\nc", []Line{del("a"), eq("b"), eq("c")}},
+		{"delete in middle", "a\nb\nc", "a\nc", []Line{eq("a"), del("b"), eq("c")}},
+		{"delete at end", "a\nb\nc", "a\nb", []Line{eq("a"), eq("b"), del("c")}},
+		{"replace every line", "a\nb", "c\nd", []Line{del("a"), del("b"), ins("c"), ins("d")}},
+		{"trailing newline", "a", "a\n", []Line{eq("a"), ins("")}},
+		{"korean", "오늘은\n맑음", "오늘은\n흐림", []Line{eq("오늘은"), del("맑음"), ins("흐림")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -8,6 +8,10 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"dailyscoop-backend/config"
+	"dailyscoop-backend/provider"
+	"dailyscoop-backend/repository"
+	"dailyscoop-backend/repository/memory"
+	"dailyscoop-backend/repository/mongodb"
 	"dailyscoop-backend/server"
 	"dailyscoop-backend/service"
 )
@@ -19,19 +23,42 @@ func main() {
 	}
 	fmt.Println(cfg.AWS.Region)
 
-	mc, err := mongo.Connect(context.Background(), options.Client().ApplyURI(cfg.Mongo.URL))
+	var repo repository.Repository
+	switch cfg.Storage {
+	case "memory":
+		repo = memory.New(cfg.Memory)
+	case "mongo":
+		mc, err := mongo.Connect(context.Background(), options.Client().ApplyURI(cfg.Mongo.URL))
+		if err != nil {
+			panic(err)
+		}
+		defer mc.Disconnect(context.Background())
+		if err := mongodb.Migrate(context.Background(), cfg.Mongo, mc); err != nil {
+			panic(err)
+		}
+		repo = mongodb.New(cfg.Mongo, mc)
+	default:
+		panic(fmt.Sprintf("unknown storage %q", cfg.Storage))
+	}
+
+	loc, err := service.LoadLocation(cfg.Server.Timezone)
 	if err != nil {
-		panic(err)
+		panic(fmt.Sprintf("server.timezone %q: %v", cfg.Server.Timezone, err))
 	}
-	defer mc.Disconnect(context.Background())
 
-	us := service.NewUserService(cfg.Mongo, mc)
-	ds := service.NewDiaryService(cfg.Mongo, mc)
-	fs := service.NewFavoriteService(cfg.Mongo, mc)
+	us := service.NewUserService(repo, cfg.Server.RefreshTokenTTL)
+	ds := service.NewDiaryService(repo, loc)
+	fs := service.NewFavoriteService(repo)
 	as := service.NewAWSService(cfg.AWS)
-	s := server.NewServer(cfg, us, ds, fs, as)
+	providers, err := provider.NewRegistry(cfg.Providers, nil)
+	if err != nil {
+		panic(err)
+	}
+	s := server.NewServer(cfg, us, ds, fs, as, providers)
 
 	s.RegisterRoutes()
+	go s.RunTrashPurger(context.Background())
+	go s.RunReportGenerator(context.Background())
 
 	s.Logger.Fatal(s.Start(cfg.Server.BindAddr))
 }
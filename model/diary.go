@@ -5,19 +5,37 @@ import (
 )
 
 const (
-	DiaryContentKey  = "content"
-	DiaryImageKey    = "image"
-	DiaryUserIDKey   = "user_id"
-	DiaryDateKey     = "date"
-	DiaryEmotionsKey = "emotions"
-	DiaryThemeKey    = "theme"
+	DiaryIDKey        = "id"
+	DiaryContentKey   = "content"
+	DiaryImageKey     = "image"
+	DiaryUserIDKey    = "user_id"
+	DiaryDateKey      = "date"
+	DiaryEmotionsKey  = "emotions"
+	DiaryThemeKey     = "theme"
+	DiaryDeletedAtKey = "deleted_at"
+	DiaryTermsKey     = "terms"
+	// DiaryTermsVersionKey holds the search.Version the terms were built
+	// with.
+	DiaryTermsVersionKey = "terms_version"
+	// DiaryTermCountsKey holds how often each term occurs, so that search
+	// results can be ranked without loading the content.
+	DiaryTermCountsKey = "term_counts"
+	DiaryTermLengthKey = "term_length"
 )
 
 type Diary struct {
+	ID       string
 	Content  string
 	Image    string
 	UserID   string `bson:"user_id"`
 	Date     time.Time
 	Emotions []string
 	Theme    string
+	// Terms are the search terms of the content, see package search.
+	Terms        []string
+	TermsVersion int            `bson:"terms_version"`
+	TermCounts   map[string]int `bson:"term_counts,omitempty"`
+	TermLength   int            `bson:"term_length"`
+	// DeletedAt is set while the diary is in the trash.
+	DeletedAt *time.Time `bson:"deleted_at,omitempty"`
 }
@@ -1,9 +1,52 @@
 package model
 
+import (
+	"time"
+)
+
+const (
+	EmotionNameKey      = "name"
+	EmotionEmojiKey     = "emoji"
+	EmotionColorKey     = "color"
+	EmotionValenceKey   = "valence"
+	EmotionOrderKey     = "order"
+	EmotionRetiredAtKey = "retired_at"
+)
+
 const (
-	EmotionNameKey = "name"
+	ValencePositive = "positive"
+	ValenceNegative = "negative"
 )
 
+// Emotion is an emotion of the catalog. Retired emotions can no longer be
+// chosen for new diaries, but stay in the catalog for the diaries that have
+// them.
 type Emotion struct {
-	Name string
+	Name    string
+	Emoji   string
+	Color   string
+	Valence string
+	// Order is the position of the emotion in lists, lowest first.
+	Order     int
+	RetiredAt *time.Time `bson:"retired_at,omitempty"`
+}
+
+func (e Emotion) Retired() bool {
+	return e.RetiredAt != nil
+}
+
+const (
+	CustomEmotionUserIDKey    = "user_id"
+	CustomEmotionNameKey      = "name"
+	CustomEmotionCreatedAtKey = "created_at"
+)
+
+// CustomEmotion is an emotion a user made for their own diaries, next to the
+// ones of the catalog.
+type CustomEmotion struct {
+	UserID    string `bson:"user_id"`
+	Name      string
+	Emoji     string
+	Valence   string
+	CreatedAt time.Time `bson:"created_at"`
 }
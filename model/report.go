@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	YearlyReportUserIDKey           = "user_id"
+	YearlyReportYearKey             = "year"
+	YearlyReportChangesKey          = "changes"
+	YearlyReportGeneratedChangesKey = "generated_changes"
+	YearlyReportGeneratedAtKey      = "generated_at"
+	YearlyReportStatsKey            = "stats"
+)
+
+// YearlyReport is the year in review of a user. Changes counts the changes
+// to the diaries of the year, and the report is up to date while
+// GeneratedChanges, the count it was generated at, is the same.
+type YearlyReport struct {
+	UserID           string `bson:"user_id"`
+	Year             int
+	Changes          int64
+	GeneratedChanges int64     `bson:"generated_changes"`
+	GeneratedAt      time.Time `bson:"generated_at"`
+	Stats            YearlyStats
+}
+
+// Generated reports whether the report has been generated at all.
+func (r YearlyReport) Generated() bool {
+	return !r.GeneratedAt.IsZero()
+}
+
+// Fresh reports whether the report reflects the current diaries.
+func (r YearlyReport) Fresh() bool {
+	return r.Generated() && r.GeneratedChanges == r.Changes
+}
+
+type YearlyStats struct {
+	Entries       int
+	Days          int
+	LongestStreak Streak      `bson:"longest_streak"`
+	TopEmotions   []NameCount `bson:"top_emotions"`
+	TopThemes     []NameCount `bson:"top_themes"`
+	// BusiestMonth is zero when nothing was written.
+	BusiestMonth        time.Month `bson:"busiest_month"`
+	BusiestMonthEntries int        `bson:"busiest_month_entries"`
+	WordCount           int        `bson:"word_count"`
+	// LongestEntry is the ID of the longest diary, and LongestEntryLength
+	// its length in characters.
+	LongestEntry       string    `bson:"longest_entry"`
+	LongestEntryDate   time.Time `bson:"longest_entry_date"`
+	LongestEntryLength int       `bson:"longest_entry_length"`
+	Highlights         []Highlight
+}
+
+// Streak is a run of consecutive days with at least one diary.
+type Streak struct {
+	Days  int
+	Start time.Time
+	End   time.Time
+}
+
+type NameCount struct {
+	Name  string
+	Count int
+}
+
+// Highlight is a photo of a diary.
+type Highlight struct {
+	DiaryID string `bson:"diary_id"`
+	Date    time.Time
+	Image   string
+}
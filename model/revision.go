@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	DiaryRevisionIDKey        = "id"
+	DiaryRevisionDiaryIDKey   = "diary_id"
+	DiaryRevisionUserIDKey    = "user_id"
+	DiaryRevisionCreatedAtKey = "created_at"
+)
+
+// DiaryRevision is a snapshot of a diary taken each time it is written.
+type DiaryRevision struct {
+	ID        string
+	DiaryID   string `bson:"diary_id"`
+	UserID    string `bson:"user_id"`
+	Content   string
+	Image     string
+	Emotions  []string
+	Theme     string
+	CreatedAt time.Time `bson:"created_at"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	SessionIDKey         = "id"
+	SessionUserIDKey     = "user_id"
+	SessionTokenHashKey  = "token_hash"
+	SessionLastSeenAtKey = "last_seen_at"
+	SessionExpiresAtKey  = "expires_at"
+)
+
+// Session is a device the user signed in on. The device holds a refresh token
+// made of the session ID and a secret, of which only the hash is kept. The
+// secret changes every time the token is used.
+type Session struct {
+	ID        string
+	UserID    string `bson:"user_id"`
+	TokenHash string `bson:"token_hash"`
+	Device    string
+	CreatedAt time.Time `bson:"created_at"`
+	// LastSeenAt is when the session was last used, to the minute.
+	LastSeenAt time.Time `bson:"last_seen_at"`
+	ExpiresAt  time.Time `bson:"expires_at"`
+}
+
+func (s Session) Expired(at time.Time) bool {
+	return !at.Before(s.ExpiresAt)
+}
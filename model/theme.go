@@ -1,9 +1,36 @@
 package model
 
+import (
+	"time"
+)
+
 const (
 	ThemeNameKey = "name"
 )
 
+// Theme is a diary theme of the catalog. Seasonal themes are only offered
+// within their availability window.
 type Theme struct {
 	Name string
+	// Titles maps language tags such as "ko" and "en" to the title of the
+	// theme in that language.
+	Titles map[string]string
+	// Assets maps asset names such as "background" to their URLs. The URLs
+	// change whenever the asset does, so they can be cached forever.
+	Assets map[string]string
+	// Palette lists the colors of the theme as #RRGGBB.
+	Palette        []string
+	AvailableFrom  *time.Time `bson:"available_from,omitempty"`
+	AvailableUntil *time.Time `bson:"available_until,omitempty"`
+}
+
+// Available reports whether the theme is offered at t.
+func (t Theme) Available(at time.Time) bool {
+	if t.AvailableFrom != nil && at.Before(*t.AvailableFrom) {
+		return false
+	}
+	if t.AvailableUntil != nil && !at.Before(*t.AvailableUntil) {
+		return false
+	}
+	return true
 }
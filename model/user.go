@@ -1,15 +1,71 @@
 package model
 
+import (
+	"time"
+)
+
 const (
 	UserIDKey           = "id"
 	UserNicknameKey     = "nickname"
 	UserPasswordKey     = "password"
 	UserProfileImageKey = "profile_image"
+	// UserMultipleEntriesKey holds whether the user keeps several diaries per
+	// day instead of one.
+	UserMultipleEntriesKey = "multiple_entries"
+	// UserTimezoneKey holds the IANA time zone in which the days of the user
+	// begin and end.
+	UserTimezoneKey = "timezone"
+	// UserTokenVersionKey holds the version access tokens of the user must
+	// carry. Raising it revokes every token issued before.
+	UserTokenVersionKey = "token_version"
+	// UserIdentitiesKey holds the provider identities linked to the user.
+	UserIdentitiesKey = "identities"
+	// UserPendingIdentitiesKey holds the identities being moved to the user
+	// by a merge.
+	UserPendingIdentitiesKey = "pending_identities"
+	UserCreatedAtKey         = "created_at"
+)
+
+const (
+	IdentityProviderKey = "provider"
+	IdentitySubjectKey  = "subject"
 )
 
+// User is an account. ID is internal to us; users sign in with their password,
+// if they set one, or with any of their linked identities.
 type User struct {
-	ID           string
-	Password     string
-	Nickname     string
-	ProfileImage string `bson:"profile_image"`
+	ID              string
+	Password        string
+	Nickname        string
+	ProfileImage    string `bson:"profile_image"`
+	MultipleEntries bool   `bson:"multiple_entries"`
+	Timezone        string `bson:"timezone,omitempty"`
+	TokenVersion    int    `bson:"token_version"`
+	// Identities holds at most one identity per provider.
+	Identities []Identity `bson:"identities,omitempty"`
+	// PendingIdentities are identities of another user that are being moved
+	// to this one. They sign in to this user already.
+	PendingIdentities []Identity `bson:"pending_identities,omitempty"`
+	// CreatedAt is zero for users who signed up before it was recorded.
+	CreatedAt time.Time `bson:"created_at,omitempty"`
+}
+
+// Identity returns the identity of the user at provider.
+func (u User) Identity(provider string) (Identity, bool) {
+	for _, identity := range u.Identities {
+		if identity.Provider == provider {
+			return identity, true
+		}
+	}
+	return Identity{}, false
+}
+
+// Identity is an account of a user at a login provider.
+type Identity struct {
+	// Provider is the name the provider is configured under.
+	Provider string
+	// Subject is the ID of the user at the provider.
+	Subject  string
+	Email    string    `bson:"email,omitempty"`
+	LinkedAt time.Time `bson:"linked_at"`
 }
@@ -0,0 +1,164 @@
+// Package period computes the calendar windows that diaries are grouped by.
+//
+// A Period is half-open: Start is the first instant in it and End the first
+// instant after it. Both are midnights in the location of the time the
+// period was computed from, so day boundaries follow the user's time zone.
+package period
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	ErrUnknownUnit      = errors.New("period: unknown unit")
+	ErrUnknownWeekStart = errors.New("period: unknown week start")
+	ErrEmptyRange       = errors.New("period: range ends before it starts")
+)
+
+// Unit is the length of a calendar period.
+type Unit string
+
+const (
+	Daily     Unit = "daily"
+	Weekly    Unit = "weekly"
+	Monthly   Unit = "monthly"
+	Quarterly Unit = "quarterly"
+	Yearly    Unit = "yearly"
+)
+
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Of returns the period of the given unit that contains t. Weeks begin on
+// weekStart.
+func Of(unit Unit, t time.Time, weekStart time.Weekday) (Period, error) {
+	day := startOfDay(t)
+	var start time.Time
+	switch unit {
+	case Daily:
+		start = day
+	case Weekly:
+		offset := (int(day.Weekday()) - int(weekStart) + 7) % 7
+		start = day.AddDate(0, 0, -offset)
+	case Monthly:
+		start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	case Quarterly:
+		month := (day.Month()-1)/3*3 + 1
+		start = time.Date(day.Year(), month, 1, 0, 0, 0, 0, day.Location())
+	case Yearly:
+		start = time.Date(day.Year(), 1, 1, 0, 0, 0, 0, day.Location())
+	default:
+		return Period{}, ErrUnknownUnit
+	}
+	return Period{Start: start, End: advance(start, unit)}, nil
+}
+
+// Range returns the period of the days from the day of from up to and
+// including the day of to.
+func Range(from, to time.Time) (Period, error) {
+	start := startOfDay(from)
+	end := startOfDay(to.In(from.Location())).AddDate(0, 0, 1)
+	if !start.Before(end) {
+		return Period{}, ErrEmptyRange
+	}
+	return Period{Start: start, End: end}, nil
+}
+
+// ParseWeekStart reads the first day of the week: "sunday", "monday" or
+// "iso". ISO weeks begin on Monday. An empty string means Sunday.
+func ParseWeekStart(s string) (time.Weekday, error) {
+	switch s {
+	case "", "sunday":
+		return time.Sunday, nil
+	case "monday", "iso":
+		return time.Monday, nil
+	}
+	return 0, ErrUnknownWeekStart
+}
+
+// Days returns the number of calendar days in the period.
+func (p Period) Days() int {
+	days := 0
+	for day := p.Start; day.Before(p.End); day = day.AddDate(0, 0, 1) {
+		days++
+	}
+	return days
+}
+
+func (p Period) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+// Count returns the number of periods Split would divide the period into,
+// without computing them.
+func (p Period) Count(unit Unit, weekStart time.Weekday) (int, error) {
+	if !p.Start.Before(p.End) {
+		return 0, nil
+	}
+	first, err := Of(unit, p.Start, weekStart)
+	if err != nil {
+		return 0, err
+	}
+	last, err := Of(unit, p.End.AddDate(0, 0, -1), weekStart)
+	if err != nil {
+		return 0, err
+	}
+	a, b := first.Start, last.Start
+	months := (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+	switch unit {
+	case Daily, Weekly:
+		// Unix seconds do not overflow over long ranges the way durations
+		// do, and rounding absorbs daylight saving changes.
+		days := int(math.Round(float64(b.Unix()-a.Unix()) / (24 * 60 * 60)))
+		if unit == Weekly {
+			return days/7 + 1, nil
+		}
+		return days + 1, nil
+	case Monthly:
+		return months + 1, nil
+	case Quarterly:
+		return months/3 + 1, nil
+	}
+	return b.Year() - a.Year() + 1, nil
+}
+
+// Split divides the period into consecutive periods of the given unit. The
+// first and last ones are cut to the bounds of p.
+func (p Period) Split(unit Unit, weekStart time.Weekday) ([]Period, error) {
+	var periods []Period
+	for start := p.Start; start.Before(p.End); {
+		q, err := Of(unit, start, weekStart)
+		if err != nil {
+			return nil, err
+		}
+		q.Start = start
+		if q.End.After(p.End) {
+			q.End = p.End
+		}
+		periods = append(periods, q)
+		start = q.End
+	}
+	return periods, nil
+}
+
+func advance(t time.Time, unit Unit) time.Time {
+	switch unit {
+	case Daily:
+		return t.AddDate(0, 0, 1)
+	case Weekly:
+		return t.AddDate(0, 0, 7)
+	case Monthly:
+		return t.AddDate(0, 1, 0)
+	case Quarterly:
+		return t.AddDate(0, 3, 0)
+	}
+	return t.AddDate(1, 0, 0)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
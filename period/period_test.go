@@ -0,0 +1,338 @@
+package period
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var seoul = mustLoadLocation("Asia/Seoul")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, seoul)
+}
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name      string
+		unit      Unit
+		t         time.Time
+		weekStart time.Weekday
+		start     time.Time
+		end       time.Time
+	}{
+		{"daily", Daily, time.Date(2024, 5, 15, 13, 30, 0, 0, seoul), time.Sunday, date(2024, 5, 15), date(2024, 5, 16)},
+		{"daily at midnight", Daily, date(2024, 5, 15), time.Sunday, date(2024, 5, 15), date(2024, 5, 16)},
+		{"daily last second", Daily, time.Date(2024, 5, 15, 23, 59, 59, 0, seoul), time.Sunday, date(2024, 5, 15), date(2024, 5, 16)},
+		// 2024-05-15 is a Wednesday.
+		{"weekly sunday start", Weekly, date(2024, 5, 15), time.Sunday, date(2024, 5, 12), date(2024, 5, 19)},
+		{"weekly monday start", Weekly, date(2024, 5, 15), time.Monday, date(2024, 5, 13), date(2024, 5, 20)},
+		{"weekly on sunday with sunday start", Weekly, date(2024, 5, 12), time.Sunday, date(2024, 5, 12), date(2024, 5, 19)},
+		{"weekly on sunday with monday start", Weekly, date(2024, 5, 12), time.Monday, date(2024, 5, 6), date(2024, 5, 13)},
+		{"weekly on saturday with sunday start", Weekly, date(2024, 5, 18), time.Sunday, date(2024, 5, 12), date(2024, 5, 19)},
+		{"weekly across years", Weekly, date(2025, 1, 1), time.Monday, date(2024, 12, 30), date(2025, 1, 6)},
+		{"monthly", Monthly, date(2024, 5, 15), time.Sunday, date(2024, 5, 1), date(2024, 6, 1)},
+		{"monthly first day", Monthly, date(2024, 5, 1), time.Sunday, date(2024, 5, 1), date(2024, 6, 1)},
+		{"monthly last day", Monthly, time.Date(2024, 5, 31, 23, 0, 0, 0, seoul), time.Sunday, date(2024, 5, 1), date(2024, 6, 1)},
+		{"monthly last day of 30", Monthly, date(2024, 4, 30), time.Sunday, date(2024, 4, 1), date(2024, 5, 1)},
+		{"monthly december", Monthly, date(2024, 12, 31), time.Sunday, date(2024, 12, 1), date(2025, 1, 1)},
+		{"monthly leap february", Monthly, date(2024, 2, 29), time.Sunday, date(2024, 2, 1), date(2024, 3, 1)},
+		{"monthly february", Monthly, date(2023, 2, 28), time.Sunday, date(2023, 2, 1), date(2023, 3, 1)},
+		{"quarterly first", Quarterly, date(2024, 2, 10), time.Sunday, date(2024, 1, 1), date(2024, 4, 1)},
+		{"quarterly last day", Quarterly, date(2024, 6, 30), time.Sunday, date(2024, 4, 1), date(2024, 7, 1)},
+		{"quarterly third", Quarterly, date(2024, 7, 1), time.Sunday, date(2024, 7, 1), date(2024, 10, 1)},
+		{"quarterly fourth last day", Quarterly, date(2024, 12, 31), time.Sunday, date(2024, 10, 1), date(2025, 1, 1)},
+		{"yearly", Yearly, date(2024, 5, 15), time.Sunday, date(2024, 1, 1), date(2025, 1, 1)},
+		{"yearly last day", Yearly, time.Date(2024, 12, 31, 23, 59, 59, 0, seoul), time.Sunday, date(2024, 1, 1), date(2025, 1, 1)},
+		{"yearly leap day", Yearly, date(2024, 2, 29), time.Sunday, date(2024, 1, 1), date(2025, 1, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Of(tt.unit, tt.t, tt.weekStart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !p.Start.Equal(tt.start) || !p.End.Equal(tt.end) {
+				t.Errorf("Of(%s, %s) = [%s, %s), want [%s, %s)", tt.unit, tt.t, p.Start, p.End, tt.start, tt.end)
+			}
+			if !p.Contains(tt.t) {
+				t.Errorf("period [%s, %s) does not contain %s", p.Start, p.End, tt.t)
+			}
+		})
+	}
+}
+
+func TestOfUnknownUnit(t *testing.T) {
+	if _, err := Of(Unit("hourly"), date(2024, 5, 15), time.Sunday); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("Of(hourly) error = %v, want %v", err, ErrUnknownUnit)
+	}
+}
+
+func TestOfContainsWholeDays(t *testing.T) {
+	tests := []struct {
+		name string
+		unit Unit
+		t    time.Time
+		days int
+	}{
+		{"january", Monthly, date(2024, 1, 31), 31},
+		{"leap february", Monthly, date(2024, 2, 29), 29},
+		{"february", Monthly, date(2023, 2, 28), 28},
+		{"april", Monthly, date(2024, 4, 30), 30},
+		{"leap first quarter", Quarterly, date(2024, 3, 31), 91},
+		{"first quarter", Quarterly, date(2023, 3, 31), 90},
+		{"fourth quarter", Quarterly, date(2024, 12, 31), 92},
+		{"leap year", Yearly, date(2024, 12, 31), 366},
+		{"year", Yearly, date(2023, 12, 31), 365},
+		{"century leap year", Yearly, date(2000, 6, 1), 366},
+		{"century", Yearly, date(1900, 6, 1), 365},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Of(tt.unit, tt.t, time.Sunday)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Days(); got != tt.days {
+				t.Errorf("Days() = %d, want %d", got, tt.days)
+			}
+			last := p.End.AddDate(0, 0, -1)
+			if !p.Contains(last) {
+				t.Errorf("period [%s, %s) does not contain its last day %s", p.Start, p.End, last)
+			}
+		})
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  time.Time
+		to    time.Time
+		start time.Time
+		end   time.Time
+		err   error
+	}{
+		{"one day", date(2024, 5, 15), date(2024, 5, 15), date(2024, 5, 15), date(2024, 5, 16), nil},
+		{"includes the last day", date(2024, 5, 1), date(2024, 5, 31), date(2024, 5, 1), date(2024, 6, 1), nil},
+		{"times are truncated", time.Date(2024, 5, 1, 18, 0, 0, 0, seoul), time.Date(2024, 5, 3, 1, 0, 0, 0, seoul), date(2024, 5, 1), date(2024, 5, 4), nil},
+		{"across leap day", date(2024, 2, 28), date(2024, 3, 1), date(2024, 2, 28), date(2024, 3, 2), nil},
+		{"to in another zone", date(2024, 5, 1), time.Date(2024, 5, 2, 20, 0, 0, 0, time.UTC), date(2024, 5, 1), date(2024, 5, 4), nil},
+		{"reversed", date(2024, 5, 15), date(2024, 5, 14), time.Time{}, time.Time{}, ErrEmptyRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Range(tt.from, tt.to)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Range error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if !p.Start.Equal(tt.start) || !p.End.Equal(tt.end) {
+				t.Errorf("Range(%s, %s) = [%s, %s), want [%s, %s)", tt.from, tt.to, p.Start, p.End, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestParseWeekStart(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Weekday
+		err  error
+	}{
+		{"", time.Sunday, nil},
+		{"sunday", time.Sunday, nil},
+		{"monday", time.Monday, nil},
+		{"iso", time.Monday, nil},
+		{"friday", 0, ErrUnknownWeekStart},
+	}
+	for _, tt := range tests {
+		got, err := ParseWeekStart(tt.s)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseWeekStart(%q) error = %v, want %v", tt.s, err, tt.err)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseWeekStart(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		p         Period
+		unit      Unit
+		weekStart time.Weekday
+		want      []Period
+	}{
+		{
+			name: "months cut to the bounds",
+			p:    Period{date(2024, 1, 15), date(2024, 3, 10)},
+			unit: Monthly,
+			want: []Period{
+				{date(2024, 1, 15), date(2024, 2, 1)},
+				{date(2024, 2, 1), date(2024, 3, 1)},
+				{date(2024, 3, 1), date(2024, 3, 10)},
+			},
+		},
+		{
+			name:      "weeks starting on monday",
+			p:         Period{date(2024, 5, 15), date(2024, 5, 29)},
+			unit:      Weekly,
+			weekStart: time.Monday,
+			want: []Period{
+				{date(2024, 5, 15), date(2024, 5, 20)},
+				{date(2024, 5, 20), date(2024, 5, 27)},
+				{date(2024, 5, 27), date(2024, 5, 29)},
+			},
+		},
+		{
+			name: "whole quarters",
+			p:    Period{date(2024, 1, 1), date(2025, 1, 1)},
+			unit: Quarterly,
+			want: []Period{
+				{date(2024, 1, 1), date(2024, 4, 1)},
+				{date(2024, 4, 1), date(2024, 7, 1)},
+				{date(2024, 7, 1), date(2024, 10, 1)},
+				{date(2024, 10, 1), date(2025, 1, 1)},
+			},
+		},
+		{
+			name: "inside one year",
+			p:    Period{date(2024, 3, 1), date(2024, 4, 1)},
+			unit: Yearly,
+			want: []Period{
+				{date(2024, 3, 1), date(2024, 4, 1)},
+			},
+		},
+		{
+			name: "days",
+			p:    Period{date(2024, 2, 28), date(2024, 3, 2)},
+			unit: Daily,
+			want: []Period{
+				{date(2024, 2, 28), date(2024, 2, 29)},
+				{date(2024, 2, 29), date(2024, 3, 1)},
+				{date(2024, 3, 1), date(2024, 3, 2)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.p.Split(tt.unit, tt.weekStart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Split returned %d periods, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) {
+					t.Errorf("period %d = [%s, %s), want [%s, %s)", i, got[i].Start, got[i].End, tt.want[i].Start, tt.want[i].End)
+				}
+			}
+		})
+	}
+}
+
+func TestDaysAcrossDST(t *testing.T) {
+	newYork := mustLoadLocation("America/New_York")
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		days int
+	}{
+		// Clocks went forward on 2024-03-10 and back on 2024-11-03.
+		{"spring forward", time.Date(2024, 3, 9, 0, 0, 0, 0, newYork), time.Date(2024, 3, 11, 0, 0, 0, 0, newYork), 3},
+		{"fall back", time.Date(2024, 11, 2, 0, 0, 0, 0, newYork), time.Date(2024, 11, 4, 0, 0, 0, 0, newYork), 3},
+		{"short day only", time.Date(2024, 3, 10, 0, 0, 0, 0, newYork), time.Date(2024, 3, 10, 0, 0, 0, 0, newYork), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Range(tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Days(); got != tt.days {
+				t.Errorf("Days() = %d, want %d", got, tt.days)
+			}
+		})
+	}
+	p, err := Of(Monthly, time.Date(2024, 3, 15, 0, 0, 0, 0, newYork), time.Sunday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Days(); got != 31 {
+		t.Errorf("Days() of March 2024 in New York = %d, want 31", got)
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name      string
+		p         Period
+		unit      Unit
+		weekStart time.Weekday
+	}{
+		{"days", Period{date(2024, 2, 28), date(2024, 3, 2)}, Daily, time.Sunday},
+		{"partial weeks", Period{date(2024, 5, 15), date(2024, 5, 29)}, Weekly, time.Monday},
+		{"whole weeks", Period{date(2024, 5, 12), date(2024, 5, 26)}, Weekly, time.Sunday},
+		{"months", Period{date(2024, 1, 15), date(2024, 3, 10)}, Monthly, time.Sunday},
+		{"months across years", Period{date(2023, 11, 30), date(2024, 2, 1)}, Monthly, time.Sunday},
+		{"quarters", Period{date(2024, 3, 31), date(2025, 4, 2)}, Quarterly, time.Sunday},
+		{"years", Period{date(2020, 12, 31), date(2024, 1, 2)}, Yearly, time.Sunday},
+		{"one day", Period{date(2024, 12, 31), date(2025, 1, 1)}, Yearly, time.Sunday},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			periods, err := tt.p.Split(tt.unit, tt.weekStart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tt.p.Count(tt.unit, tt.weekStart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != len(periods) {
+				t.Errorf("Count() = %d, want %d", got, len(periods))
+			}
+		})
+	}
+}
+
+func TestCountLongRange(t *testing.T) {
+	p, err := Range(date(1, 1, 1), date(9999, 12, 31))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		unit Unit
+		min  int
+	}{
+		{Daily, 3000000},
+		{Weekly, 500000},
+		{Monthly, 119000},
+		{Quarterly, 39000},
+		{Yearly, 9999},
+	}
+	for _, tt := range tests {
+		got, err := p.Count(tt.unit, time.Sunday)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got < tt.min {
+			t.Errorf("Count(%s) = %d, want at least %d", tt.unit, got, tt.min)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"dailyscoop-backend/config"
+)
+
+// privateRelayDomain is the domain of the addresses Apple makes up for users
+// who hide their email. Mail to them is only relayed from registered
+// senders.
+const privateRelayDomain = "@privaterelay.appleid.com"
+
+// apple verifies Sign in with Apple identity tokens.
+type apple struct {
+	verifier *idTokenVerifier
+}
+
+func newApple(cfg config.ProviderConfig, client *http.Client) (Provider, error) {
+	if cfg.ClientID == "" || cfg.JWKSURL == "" || len(cfg.Issuers) == 0 {
+		return nil, errIncompleteConfig
+	}
+	return &apple{verifier: &idTokenVerifier{
+		clientID: cfg.ClientID,
+		issuers:  cfg.Issuers,
+		keys:     &keySet{url: cfg.JWKSURL, client: client},
+	}}, nil
+}
+
+// Authenticate verifies the identity token. Apple only puts the email in
+// the first token of a user and never their name, so accounts are keyed by
+// the subject, and the name comes from the client.
+func (a *apple) Authenticate(ctx context.Context, creds Credentials) (Identity, error) {
+	claims, err := a.verifier.verify(ctx, creds.IDToken)
+	if err != nil {
+		return Identity{}, err
+	}
+	sub := stringClaim(claims, "sub")
+	if sub == "" {
+		return Identity{}, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+	identity := Identity{
+		Subject:  sub,
+		LegacyID: "apple:" + sub,
+		Nickname: creds.Name,
+	}
+	if email := stringClaim(claims, "email"); email != "" && boolClaim(claims, "email_verified") {
+		identity.Email = email
+		identity.PrivateEmail = boolClaim(claims, "is_private_email") ||
+			strings.HasSuffix(strings.ToLower(email), privateRelayDomain)
+	}
+	if identity.Nickname == "" && !identity.PrivateEmail {
+		if i := strings.Index(identity.Email, "@"); i > 0 {
+			identity.Nickname = identity.Email[:i]
+		}
+	}
+	return identity, nil
+}
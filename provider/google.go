@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"dailyscoop-backend/config"
+)
+
+// google verifies Google ID tokens.
+type google struct {
+	verifier *idTokenVerifier
+}
+
+func newGoogle(cfg config.ProviderConfig, client *http.Client) (Provider, error) {
+	if cfg.ClientID == "" || cfg.JWKSURL == "" || len(cfg.Issuers) == 0 {
+		return nil, errIncompleteConfig
+	}
+	return &google{verifier: &idTokenVerifier{
+		clientID: cfg.ClientID,
+		issuers:  cfg.Issuers,
+		keys:     &keySet{url: cfg.JWKSURL, client: client},
+	}}, nil
+}
+
+// Authenticate verifies the ID token. Accounts made before identities were
+// linked are keyed by the email, so only a verified email is accepted.
+func (g *google) Authenticate(ctx context.Context, creds Credentials) (Identity, error) {
+	claims, err := g.verifier.verify(ctx, creds.IDToken)
+	if err != nil {
+		return Identity{}, err
+	}
+	sub := stringClaim(claims, "sub")
+	if sub == "" {
+		return Identity{}, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+	email := stringClaim(claims, "email")
+	if email == "" {
+		return Identity{}, fmt.Errorf("%w: token has no email", ErrInvalidCredentials)
+	}
+	if !boolClaim(claims, "email_verified") {
+		return Identity{}, fmt.Errorf("%w: email is not verified", ErrInvalidCredentials)
+	}
+	return Identity{
+		Subject:      sub,
+		LegacyID:     email,
+		Email:        email,
+		Nickname:     stringClaim(claims, "name"),
+		ProfileImage: stringClaim(claims, "picture"),
+	}, nil
+}
@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	// keysMaxAge is how long fetched keys are used before they are fetched
+	// again.
+	keysMaxAge = time.Hour
+	// keysMinInterval keeps tokens with unknown key IDs from making us
+	// fetch the keys on every request.
+	keysMinInterval = time.Minute
+)
+
+// keySet holds the RSA keys a JWKS URL serves, fetching them again when they
+// get old or a token names a key it does not have.
+type keySet struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+func (ks *keySet) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	age := time.Since(ks.fetchedAt)
+	key, ok := ks.keys[kid]
+	if ok && age < keysMaxAge {
+		return key, nil
+	}
+	if !ok && ks.keys != nil && age < keysMinInterval {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidCredentials, kid)
+	}
+	keys, err := ks.fetch(ctx)
+	if err != nil {
+		if ok {
+			// The key was known; it is more likely still valid than not.
+			return key, nil
+		}
+		return nil, err
+	}
+	ks.keys = keys
+	ks.fetchedAt = time.Now()
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidCredentials, kid)
+}
+
+func (ks *keySet) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("provider: %s responded %d", ks.url, resp.StatusCode)
+	}
+	var set struct {
+		Keys []struct {
+			Kty string
+			Kid string
+			N   string
+			E   string
+		}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("provider: key %q: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("provider: key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+// idTokenVerifier verifies RS256 ID tokens issued to a client.
+type idTokenVerifier struct {
+	clientID string
+	issuers  []string
+	keys     *keySet
+}
+
+// verify checks the signature, expiry, issuer and audience of token and
+// returns its claims.
+func (v *idTokenVerifier) verify(ctx context.Context, token string) (jwt.MapClaims, error) {
+	if token == "" {
+		return nil, ErrInvalidCredentials
+	}
+	var fetchErr error
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		key, err := v.keys.key(ctx, kid)
+		if err != nil && !errors.Is(err, ErrInvalidCredentials) {
+			fetchErr = err
+		}
+		return key, err
+	})
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if !v.validIssuer(claims) {
+		return nil, fmt.Errorf("%w: unexpected issuer %v", ErrInvalidCredentials, claims["iss"])
+	}
+	if !claims.VerifyAudience(v.clientID, true) {
+		return nil, fmt.Errorf("%w: unexpected audience %v", ErrInvalidCredentials, claims["aud"])
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("%w: token does not expire", ErrInvalidCredentials)
+	}
+	return claims, nil
+}
+
+func (v *idTokenVerifier) validIssuer(claims jwt.MapClaims) bool {
+	for _, issuer := range v.issuers {
+		if claims.VerifyIssuer(issuer, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringClaim returns the claim if it is a string.
+func stringClaim(claims jwt.MapClaims, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+// boolClaim returns the claim as a bool. Some providers send booleans as the
+// strings "true" and "false".
+func boolClaim(claims jwt.MapClaims, name string) bool {
+	switch v := claims[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
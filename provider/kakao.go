@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"dailyscoop-backend/config"
+)
+
+// kakao exchanges access tokens for the user at the Kakao user API.
+type kakao struct {
+	userInfoURL string
+	client      *http.Client
+}
+
+func newKakao(cfg config.ProviderConfig, client *http.Client) (Provider, error) {
+	if cfg.UserInfoURL == "" {
+		return nil, errIncompleteConfig
+	}
+	return &kakao{userInfoURL: cfg.UserInfoURL, client: client}, nil
+}
+
+func (k *kakao) Authenticate(ctx context.Context, creds Credentials) (Identity, error) {
+	if creds.AccessToken == "" {
+		return Identity{}, ErrInvalidCredentials
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.userInfoURL, nil)
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+creds.AccessToken)
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return Identity{}, err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		ID         int64
+		Properties struct {
+			Nickname string
+		}
+		KakaoAccount struct {
+			Email string
+		} `json:"kakao_account"`
+		Message string `json:"msg"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Identity{}, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusBadRequest {
+		return Identity{}, fmt.Errorf("%w: %s", ErrInvalidCredentials, result.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Identity{}, fmt.Errorf("provider: kakao responded %d: %s", resp.StatusCode, result.Message)
+	}
+	id := strconv.FormatInt(result.ID, 10)
+	return Identity{
+		Subject:  id,
+		LegacyID: id,
+		Email:    result.KakaoAccount.Email,
+		Nickname: result.Properties.Nickname,
+	}, nil
+}
@@ -0,0 +1,105 @@
+// Package provider signs users in through social login providers.
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"dailyscoop-backend/config"
+)
+
+var (
+	// ErrInvalidCredentials means the provider did not accept the
+	// credentials of the login request.
+	ErrInvalidCredentials = errors.New("provider: invalid credentials")
+	ErrUnknownProvider    = errors.New("provider: unknown provider")
+)
+
+// Credentials are what a client got from a provider to sign in with. Which
+// of them is used depends on the provider.
+type Credentials struct {
+	AccessToken string
+	IDToken     string
+	// Name is the name of the user the client got along with the
+	// credentials, for providers that leave it out of their tokens.
+	Name string
+}
+
+// Identity is a user as a provider knows them.
+type Identity struct {
+	// Subject is the ID of the user at the provider.
+	Subject string
+	// LegacyID is the ID accounts of the identity were made with before
+	// identities were linked to users.
+	LegacyID string
+	Email    string
+	// PrivateEmail means Email is a relay address the provider made up, so
+	// it cannot tell the user apart from their other accounts.
+	PrivateEmail bool
+	Nickname     string
+	ProfileImage string
+}
+
+type Provider interface {
+	// Authenticate verifies the credentials with the provider and returns
+	// whom they belong to. Credentials the provider rejects fail with
+	// ErrInvalidCredentials.
+	Authenticate(ctx context.Context, creds Credentials) (Identity, error)
+}
+
+// Factory makes a provider of a type from its config.
+type Factory func(cfg config.ProviderConfig, client *http.Client) (Provider, error)
+
+var factories = map[string]Factory{
+	"kakao":  newKakao,
+	"google": newGoogle,
+	"apple":  newApple,
+}
+
+// Registry holds the configured providers by name.
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry makes the providers of cfgs, whose types default to their
+// names. Providers whose config is incomplete are left out, so that a
+// deployment only offers the logins it has set up.
+func NewRegistry(cfgs map[string]config.ProviderConfig, client *http.Client) (*Registry, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	r := &Registry{providers: make(map[string]Provider, len(cfgs))}
+	for name, cfg := range cfgs {
+		typ := cfg.Type
+		if typ == "" {
+			typ = name
+		}
+		factory, ok := factories[typ]
+		if !ok {
+			return nil, fmt.Errorf("provider %q: unknown type %q", name, typ)
+		}
+		p, err := factory(cfg, client)
+		if errors.Is(err, errIncompleteConfig) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("provider %q: %w", name, err)
+		}
+		r.providers[name] = p
+	}
+	return r, nil
+}
+
+// Provider returns the provider configured as name.
+func (r *Registry) Provider(name string) (Provider, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+var errIncompleteConfig = errors.New("provider: incomplete config")
@@ -0,0 +1,267 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+
+	"dailyscoop-backend/config"
+)
+
+func TestKakao(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"msg": "this access token does not exist", "code": -401})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":         1234567890,
+			"properties": map[string]string{"nickname": "카카오"},
+		})
+	}))
+	defer srv.Close()
+	r, err := NewRegistry(map[string]config.ProviderConfig{
+		"kakao": {UserInfoURL: srv.URL},
+	}, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := r.Provider("kakao")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity, err := p.Authenticate(context.Background(), Credentials{AccessToken: "good"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Identity{Subject: "1234567890", LegacyID: "1234567890", Nickname: "카카오"}
+	if identity != want {
+		t.Errorf("got %+v, want %+v", identity, want)
+	}
+	if _, err := p.Authenticate(context.Background(), Credentials{AccessToken: "bad"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("bad token: got %v, want ErrInvalidCredentials", err)
+	}
+}
+
+// issuer signs ID tokens with a key it serves as a JWKS.
+type issuer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+}
+
+func newIssuer(t *testing.T) *issuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iss := &issuer{key: key}
+	iss.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(iss.Close)
+	return iss
+}
+
+func (iss *issuer) sign(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	s, err := token.SignedString(iss.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestGoogle(t *testing.T) {
+	iss := newIssuer(t)
+	r, err := NewRegistry(map[string]config.ProviderConfig{
+		"google": {ClientID: "client", JWKSURL: iss.URL, Issuers: []string{"https://accounts.google.com"}},
+	}, iss.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := r.Provider("google")
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            "https://accounts.google.com",
+			"aud":            "client",
+			"sub":            "1087",
+			"email":          "user@example.com",
+			"email_verified": true,
+			"name":           "구글",
+			"picture":        "https://example.com/user.png",
+			"exp":            time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	identity, err := p.Authenticate(context.Background(), Credentials{IDToken: iss.sign(t, valid())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Identity{
+		Subject:      "1087",
+		LegacyID:     "user@example.com",
+		Email:        "user@example.com",
+		Nickname:     "구글",
+		ProfileImage: "https://example.com/user.png",
+	}
+	if identity != want {
+		t.Errorf("got %+v, want %+v", identity, want)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		modify func(jwt.MapClaims)
+	}{
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		{"other audience", func(c jwt.MapClaims) { c["aud"] = "other" }},
+		{"other issuer", func(c jwt.MapClaims) { c["iss"] = "https://example.com" }},
+		{"no expiry", func(c jwt.MapClaims) { delete(c, "exp") }},
+		{"no subject", func(c jwt.MapClaims) { delete(c, "sub") }},
+		{"empty subject", func(c jwt.MapClaims) { c["sub"] = "" }},
+		{"no email", func(c jwt.MapClaims) { delete(c, "email") }},
+		{"unverified email", func(c jwt.MapClaims) { c["email_verified"] = false }},
+		{"unverified email as a string", func(c jwt.MapClaims) { c["email_verified"] = "false" }},
+		{"email not known to be verified", func(c jwt.MapClaims) { delete(c, "email_verified") }},
+	} {
+		claims := valid()
+		tt.modify(claims)
+		if _, err := p.Authenticate(context.Background(), Credentials{IDToken: iss.sign(t, claims)}); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%s: got %v, want ErrInvalidCredentials", tt.name, err)
+		}
+	}
+
+	other := newIssuer(t)
+	if _, err := p.Authenticate(context.Background(), Credentials{IDToken: other.sign(t, valid())}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("token signed with another key: got %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r, err := NewRegistry(map[string]config.ProviderConfig{
+		// Google without a client ID is left out.
+		"google": {JWKSURL: "https://example.com/certs", Issuers: []string{"accounts.google.com"}},
+		"kakao":  {UserInfoURL: "https://example.com/me"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Provider("kakao"); err != nil {
+		t.Errorf("kakao: %v", err)
+	}
+	for _, name := range []string{"google", "naver"} {
+		if _, err := r.Provider(name); !errors.Is(err, ErrUnknownProvider) {
+			t.Errorf("%s: got %v, want ErrUnknownProvider", name, err)
+		}
+	}
+	if _, err := NewRegistry(map[string]config.ProviderConfig{"naver": {}}, nil); err == nil {
+		t.Error("a provider of an unknown type was accepted")
+	}
+}
+
+func TestApple(t *testing.T) {
+	iss := newIssuer(t)
+	r, err := NewRegistry(map[string]config.ProviderConfig{
+		"apple": {ClientID: "com.example.dailyscoop", JWKSURL: iss.URL, Issuers: []string{"https://appleid.apple.com"}},
+	}, iss.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := r.Provider("apple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := func(extra jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss": "https://appleid.apple.com",
+			"aud": "com.example.dailyscoop",
+			"sub": "001234.abcd",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		given  string
+		want   Identity
+	}{
+		{
+			name:   "shared email",
+			claims: claims(jwt.MapClaims{"email": "user@example.com", "email_verified": "true"}),
+			want:   Identity{Subject: "001234.abcd", LegacyID: "apple:001234.abcd", Email: "user@example.com", Nickname: "user"},
+		},
+		{
+			name:   "private relay",
+			claims: claims(jwt.MapClaims{"email": "x7k2@privaterelay.appleid.com", "email_verified": true, "is_private_email": "true"}),
+			given:  "애플",
+			want:   Identity{Subject: "001234.abcd", LegacyID: "apple:001234.abcd", Email: "x7k2@privaterelay.appleid.com", PrivateEmail: true, Nickname: "애플"},
+		},
+		{
+			name:   "relay without the flag",
+			claims: claims(jwt.MapClaims{"email": "x7k2@privaterelay.appleid.com", "email_verified": "true"}),
+			want:   Identity{Subject: "001234.abcd", LegacyID: "apple:001234.abcd", Email: "x7k2@privaterelay.appleid.com", PrivateEmail: true},
+		},
+		{
+			name:   "unverified email",
+			claims: claims(jwt.MapClaims{"email": "user@example.com", "email_verified": "false"}),
+			want:   Identity{Subject: "001234.abcd", LegacyID: "apple:001234.abcd"},
+		},
+		{
+			name:   "email without a domain",
+			claims: claims(jwt.MapClaims{"email": "user", "email_verified": true}),
+			want:   Identity{Subject: "001234.abcd", LegacyID: "apple:001234.abcd", Email: "user"},
+		},
+		{
+			name:   "email without a local part",
+			claims: claims(jwt.MapClaims{"email": "@example.com", "email_verified": true}),
+			want:   Identity{Subject: "001234.abcd", LegacyID: "apple:001234.abcd", Email: "@example.com"},
+		},
+		{
+			name:   "later sign in",
+			claims: claims(nil),
+			want:   Identity{Subject: "001234.abcd", LegacyID: "apple:001234.abcd"},
+		},
+	}
+	for _, tt := range tests {
+		identity, err := p.Authenticate(context.Background(), Credentials{IDToken: iss.sign(t, tt.claims), Name: tt.given})
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if identity != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, identity, tt.want)
+		}
+	}
+
+	if _, err := p.Authenticate(context.Background(), Credentials{IDToken: iss.sign(t, claims(jwt.MapClaims{"aud": "com.example.other"}))}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("token for another app: got %v, want ErrInvalidCredentials", err)
+	}
+}
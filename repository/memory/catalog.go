@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+type EmotionRepository struct {
+	mu       sync.RWMutex
+	emotions []model.Emotion
+}
+
+func NewEmotionRepository(names ...string) *EmotionRepository {
+	er := &EmotionRepository{}
+	for i, name := range names {
+		er.emotions = append(er.emotions, model.Emotion{Name: name, Order: i})
+	}
+	return er
+}
+
+func (er *EmotionRepository) FindAll(ctx context.Context) ([]model.Emotion, error) {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	emotions := make([]model.Emotion, 0, len(er.emotions))
+	for _, emotion := range er.emotions {
+		emotions = append(emotions, cloneEmotion(emotion))
+	}
+	sort.SliceStable(emotions, func(i, j int) bool {
+		if emotions[i].Order != emotions[j].Order {
+			return emotions[i].Order < emotions[j].Order
+		}
+		return emotions[i].Name < emotions[j].Name
+	})
+	return emotions, nil
+}
+
+func (er *EmotionRepository) FindByName(ctx context.Context, name string) (model.Emotion, error) {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	if i := er.index(name); i >= 0 {
+		return cloneEmotion(er.emotions[i]), nil
+	}
+	return model.Emotion{}, repository.ErrNotFound
+}
+
+func (er *EmotionRepository) Exists(ctx context.Context, name string) (bool, error) {
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	return er.index(name) >= 0, nil
+}
+
+func (er *EmotionRepository) Insert(ctx context.Context, emotion model.Emotion) error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	if er.index(emotion.Name) >= 0 {
+		return repository.ErrDuplicate
+	}
+	er.emotions = append(er.emotions, cloneEmotion(emotion))
+	return nil
+}
+
+func (er *EmotionRepository) Update(ctx context.Context, emotion model.Emotion) error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	i := er.index(emotion.Name)
+	if i < 0 {
+		return repository.ErrNotFound
+	}
+	er.emotions[i].Emoji = emotion.Emoji
+	er.emotions[i].Color = emotion.Color
+	er.emotions[i].Valence = emotion.Valence
+	return nil
+}
+
+func (er *EmotionRepository) Retire(ctx context.Context, name string, at time.Time) error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	i := er.index(name)
+	if i < 0 {
+		return repository.ErrNotFound
+	}
+	er.emotions[i].RetiredAt = &at
+	return nil
+}
+
+func (er *EmotionRepository) Reorder(ctx context.Context, names []string) error {
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	for order, name := range names {
+		if i := er.index(name); i >= 0 {
+			er.emotions[i].Order = order
+		}
+	}
+	return nil
+}
+
+func (er *EmotionRepository) index(name string) int {
+	for i, emotion := range er.emotions {
+		if emotion.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func cloneEmotion(emotion model.Emotion) model.Emotion {
+	if emotion.RetiredAt != nil {
+		retiredAt := *emotion.RetiredAt
+		emotion.RetiredAt = &retiredAt
+	}
+	return emotion
+}
+
+type ThemeRepository struct {
+	mu     sync.RWMutex
+	themes []model.Theme
+}
+
+func NewThemeRepository(names ...string) *ThemeRepository {
+	tr := &ThemeRepository{}
+	for _, name := range names {
+		tr.themes = append(tr.themes, model.Theme{Name: name})
+	}
+	return tr
+}
+
+func (tr *ThemeRepository) FindAll(ctx context.Context) ([]model.Theme, error) {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	themes := make([]model.Theme, 0, len(tr.themes))
+	for _, theme := range tr.themes {
+		themes = append(themes, cloneTheme(theme))
+	}
+	return themes, nil
+}
+
+func (tr *ThemeRepository) Exists(ctx context.Context, name string) (bool, error) {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	for _, theme := range tr.themes {
+		if theme.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func cloneTheme(theme model.Theme) model.Theme {
+	theme.Titles = cloneStrings(theme.Titles)
+	theme.Assets = cloneStrings(theme.Assets)
+	theme.Palette = append([]string(nil), theme.Palette...)
+	if theme.AvailableFrom != nil {
+		from := *theme.AvailableFrom
+		theme.AvailableFrom = &from
+	}
+	if theme.AvailableUntil != nil {
+		until := *theme.AvailableUntil
+		theme.AvailableUntil = &until
+	}
+	return theme
+}
+
+func cloneStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"dailyscoop-backend/model"
+)
+
+type CustomEmotionRepository struct {
+	mu       sync.RWMutex
+	emotions []model.CustomEmotion
+}
+
+func NewCustomEmotionRepository() *CustomEmotionRepository {
+	return &CustomEmotionRepository{}
+}
+
+func (cr *CustomEmotionRepository) Insert(ctx context.Context, emotion model.CustomEmotion) error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.emotions = append(cr.emotions, emotion)
+	return nil
+}
+
+func (cr *CustomEmotionRepository) FindByUserID(ctx context.Context, userID string) ([]model.CustomEmotion, error) {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	var emotions []model.CustomEmotion
+	for _, emotion := range cr.emotions {
+		if emotion.UserID == userID {
+			emotions = append(emotions, emotion)
+		}
+	}
+	return emotions, nil
+}
+
+func (cr *CustomEmotionRepository) Exists(ctx context.Context, userID string, name string) (bool, error) {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	for _, emotion := range cr.emotions {
+		if emotion.UserID == userID && emotion.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (cr *CustomEmotionRepository) Delete(ctx context.Context, userID string, name string) error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	for i, emotion := range cr.emotions {
+		if emotion.UserID == userID && emotion.Name == name {
+			cr.emotions = append(cr.emotions[:i], cr.emotions[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (cr *CustomEmotionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	emotions := cr.emotions[:0]
+	for _, emotion := range cr.emotions {
+		if emotion.UserID != userID {
+			emotions = append(emotions, emotion)
+		}
+	}
+	cr.emotions = emotions
+	return nil
+}
+
+func (cr *CustomEmotionRepository) Reassign(ctx context.Context, fromUserID string, toUserID string) error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	for i := range cr.emotions {
+		if cr.emotions[i].UserID == fromUserID {
+			cr.emotions[i].UserID = toUserID
+		}
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+	"dailyscoop-backend/search"
+)
+
+type DiaryRepository struct {
+	mu      sync.RWMutex
+	diaries []model.Diary
+}
+
+func NewDiaryRepository() *DiaryRepository {
+	return &DiaryRepository{}
+}
+
+func (dr *DiaryRepository) Find(ctx context.Context, query repository.DiaryQuery) ([]model.Diary, error) {
+	diaries := dr.find(func(diary model.Diary) bool {
+		return matchesQuery(diary, query)
+	}, query.Sort)
+	sortDiariesByID(diaries, query.Sort)
+	if query.Limit > 0 && len(diaries) > query.Limit {
+		diaries = diaries[:query.Limit]
+	}
+	if len(query.Fields) > 0 {
+		for i := range diaries {
+			diaries[i] = project(diaries[i], query.Fields)
+		}
+	}
+	return diaries, nil
+}
+
+func (dr *DiaryRepository) FindMatches(ctx context.Context, query repository.DiaryQuery, phrase string) ([]repository.DiaryMatch, error) {
+	query.After = nil
+	diaries := dr.find(func(diary model.Diary) bool {
+		return matchesQuery(diary, query)
+	}, query.Sort)
+	sortDiariesByID(diaries, query.Sort)
+	matches := make([]repository.DiaryMatch, 0, len(diaries))
+	for _, diary := range diaries {
+		counts := make(map[string]int, len(query.Terms))
+		for _, term := range query.Terms {
+			if n, ok := diary.TermCounts[term]; ok {
+				counts[term] = n
+			}
+		}
+		matches = append(matches, repository.DiaryMatch{
+			ID:         diary.ID,
+			TermCounts: counts,
+			TermLength: diary.TermLength,
+			Phrase:     search.ContainsPhrase(diary.Content, phrase),
+		})
+	}
+	return matches, nil
+}
+
+// matchesQuery reports whether the diary is one of the diaries the query
+// selects.
+func matchesQuery(diary model.Diary, query repository.DiaryQuery) bool {
+	if diary.UserID != query.UserID {
+		return false
+	}
+	if len(query.IDs) > 0 && !containsAny(query.IDs, []string{diary.ID}) {
+		return false
+	}
+	if !containsAll(diary.Terms, query.Terms) {
+		return false
+	}
+	if len(query.Emotions) > 0 {
+		if query.AllEmotions && !containsAll(diary.Emotions, query.Emotions) {
+			return false
+		}
+		if !query.AllEmotions && !containsAny(diary.Emotions, query.Emotions) {
+			return false
+		}
+	}
+	if query.Theme != "" && diary.Theme != query.Theme {
+		return false
+	}
+	if !query.From.IsZero() && diary.Date.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && !diary.Date.Before(query.To) {
+		return false
+	}
+	if query.HasImage != nil && (diary.Image != "") != *query.HasImage {
+		return false
+	}
+	if utf8.RuneCountInString(diary.Content) < query.MinLength {
+		return false
+	}
+	return query.After == nil || after(diary, *query.After, query.Sort)
+}
+
+func (dr *DiaryRepository) FindInRange(ctx context.Context, userID string, from, to time.Time, sort int) ([]model.Diary, error) {
+	return dr.find(func(diary model.Diary) bool {
+		return diary.UserID == userID && inRange(diary.Date, from, to)
+	}, sort), nil
+}
+
+func (dr *DiaryRepository) FindOneInRange(ctx context.Context, userID string, from, to time.Time) (model.Diary, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	i := dr.indexInRange(userID, from, to)
+	if i < 0 {
+		return model.Diary{}, repository.ErrNotFound
+	}
+	return cloneDiary(dr.diaries[i]), nil
+}
+
+func (dr *DiaryRepository) FindByID(ctx context.Context, userID string, id string) (model.Diary, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	i := dr.indexByID(userID, id, false)
+	if i < 0 {
+		return model.Diary{}, repository.ErrNotFound
+	}
+	return cloneDiary(dr.diaries[i]), nil
+}
+
+func (dr *DiaryRepository) Insert(ctx context.Context, diary model.Diary) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.diaries = append(dr.diaries, cloneDiary(diary))
+	return nil
+}
+
+func (dr *DiaryRepository) UpsertInRange(ctx context.Context, diary model.Diary, from, to time.Time) (model.Diary, error) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	i := dr.indexInRange(diary.UserID, from, to)
+	if i < 0 {
+		dr.diaries = append(dr.diaries, cloneDiary(diary))
+		return cloneDiary(diary), nil
+	}
+	diary.ID = dr.diaries[i].ID
+	diary.Date = dr.diaries[i].Date
+	dr.diaries[i] = cloneDiary(diary)
+	return cloneDiary(diary), nil
+}
+
+func (dr *DiaryRepository) Update(ctx context.Context, diary model.Diary) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	i := dr.indexByID(diary.UserID, diary.ID, false)
+	if i < 0 {
+		return repository.ErrNotFound
+	}
+	diary.DeletedAt = nil
+	dr.diaries[i] = cloneDiary(diary)
+	return nil
+}
+
+func (dr *DiaryRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	dr.deleteWhere(func(diary model.Diary) bool {
+		return diary.UserID == userID
+	})
+	return nil
+}
+
+func (dr *DiaryRepository) Trash(ctx context.Context, userID string, id string, at time.Time) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	i := dr.indexByID(userID, id, false)
+	if i < 0 {
+		return repository.ErrNotFound
+	}
+	dr.diaries[i].DeletedAt = &at
+	return nil
+}
+
+func (dr *DiaryRepository) FindTrashed(ctx context.Context, userID string) ([]model.Diary, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	var diaries []model.Diary
+	for _, diary := range dr.diaries {
+		if diary.UserID == userID && diary.DeletedAt != nil {
+			diaries = append(diaries, cloneDiary(diary))
+		}
+	}
+	sort.SliceStable(diaries, func(i, j int) bool {
+		return diaries[i].DeletedAt.After(*diaries[j].DeletedAt)
+	})
+	return diaries, nil
+}
+
+func (dr *DiaryRepository) FindTrashedByID(ctx context.Context, userID string, id string) (model.Diary, error) {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	i := dr.indexByID(userID, id, true)
+	if i < 0 {
+		return model.Diary{}, repository.ErrNotFound
+	}
+	return cloneDiary(dr.diaries[i]), nil
+}
+
+func (dr *DiaryRepository) Restore(ctx context.Context, userID string, id string) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	i := dr.indexByID(userID, id, true)
+	if i < 0 {
+		return repository.ErrNotFound
+	}
+	dr.diaries[i].DeletedAt = nil
+	return nil
+}
+
+func (dr *DiaryRepository) DeleteTrashed(ctx context.Context, userID string, id string) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	i := dr.indexByID(userID, id, true)
+	if i < 0 {
+		return repository.ErrNotFound
+	}
+	dr.diaries = append(dr.diaries[:i], dr.diaries[i+1:]...)
+	return nil
+}
+
+func (dr *DiaryRepository) PurgeTrashed(ctx context.Context, before time.Time) ([]model.Diary, error) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	var purged []model.Diary
+	dr.deleteWhere(func(diary model.Diary) bool {
+		if diary.DeletedAt != nil && diary.DeletedAt.Before(before) {
+			purged = append(purged, cloneDiary(diary))
+			return true
+		}
+		return false
+	})
+	return purged, nil
+}
+
+// find returns the diaries outside the trash that match.
+func (dr *DiaryRepository) find(match func(diary model.Diary) bool, sort int) []model.Diary {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	var diaries []model.Diary
+	for _, diary := range dr.diaries {
+		if diary.DeletedAt == nil && match(diary) {
+			diaries = append(diaries, cloneDiary(diary))
+		}
+	}
+	sortDiaries(diaries, sort)
+	return diaries
+}
+
+func (dr *DiaryRepository) deleteWhere(match func(diary model.Diary) bool) {
+	diaries := dr.diaries[:0]
+	for _, diary := range dr.diaries {
+		if !match(diary) {
+			diaries = append(diaries, diary)
+		}
+	}
+	dr.diaries = diaries
+}
+
+func (dr *DiaryRepository) indexInRange(userID string, from, to time.Time) int {
+	for i, diary := range dr.diaries {
+		if diary.UserID == userID && diary.DeletedAt == nil && inRange(diary.Date, from, to) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (dr *DiaryRepository) indexByID(userID string, id string, trashed bool) int {
+	for i, diary := range dr.diaries {
+		if diary.UserID == userID && diary.ID == id && (diary.DeletedAt != nil) == trashed {
+			return i
+		}
+	}
+	return -1
+}
+
+// after reports whether diary comes after the cursor in the sort order.
+func after(diary model.Diary, cursor repository.DiaryCursor, order int) bool {
+	if !diary.Date.Equal(cursor.Date) {
+		if order < 0 {
+			return diary.Date.Before(cursor.Date)
+		}
+		return diary.Date.After(cursor.Date)
+	}
+	if order < 0 {
+		return diary.ID < cursor.ID
+	}
+	return diary.ID > cursor.ID
+}
+
+// project clears the fields of diary that are not listed.
+func project(diary model.Diary, fields []string) model.Diary {
+	projected := model.Diary{
+		ID:        diary.ID,
+		UserID:    diary.UserID,
+		Date:      diary.Date,
+		Terms:     diary.Terms,
+		DeletedAt: diary.DeletedAt,
+	}
+	for _, field := range fields {
+		switch field {
+		case model.DiaryContentKey:
+			projected.Content = diary.Content
+		case model.DiaryImageKey:
+			projected.Image = diary.Image
+		case model.DiaryEmotionsKey:
+			projected.Emotions = diary.Emotions
+		case model.DiaryThemeKey:
+			projected.Theme = diary.Theme
+		}
+	}
+	return projected
+}
+
+func containsAll(set []string, items []string) bool {
+	for _, item := range items {
+		found := false
+		for _, s := range set {
+			if s == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAny(set []string, items []string) bool {
+	for _, item := range items {
+		for _, s := range set {
+			if s == item {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inRange(t, from, to time.Time) bool {
+	return !t.Before(from) && t.Before(to)
+}
+
+func (dr *DiaryRepository) Reassign(ctx context.Context, fromUserID string, toUserID string) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+	for i := range dr.diaries {
+		if dr.diaries[i].UserID == fromUserID {
+			dr.diaries[i].UserID = toUserID
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"dailyscoop-backend/model"
+)
+
+type FavoriteRepository struct {
+	mu        sync.RWMutex
+	favorites []model.Favorite
+}
+
+func NewFavoriteRepository() *FavoriteRepository {
+	return &FavoriteRepository{}
+}
+
+func (fr *FavoriteRepository) Insert(ctx context.Context, favorite model.Favorite) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.favorites = append(fr.favorites, favorite)
+	return nil
+}
+
+func (fr *FavoriteRepository) FindByUserID(ctx context.Context, userID string) ([]model.Favorite, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	var favorites []model.Favorite
+	for _, favorite := range fr.favorites {
+		if favorite.UserID == userID {
+			favorites = append(favorites, favorite)
+		}
+	}
+	return favorites, nil
+}
+
+func (fr *FavoriteRepository) Delete(ctx context.Context, userID string, quote string) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	for i, favorite := range fr.favorites {
+		if favorite.UserID == userID && favorite.Quote == quote {
+			fr.favorites = append(fr.favorites[:i], fr.favorites[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (fr *FavoriteRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	favorites := fr.favorites[:0]
+	for _, favorite := range fr.favorites {
+		if favorite.UserID != userID {
+			favorites = append(favorites, favorite)
+		}
+	}
+	fr.favorites = favorites
+	return nil
+}
+
+func (fr *FavoriteRepository) Exists(ctx context.Context, userID string, quote string) (bool, error) {
+	fr.mu.RLock()
+	defer fr.mu.RUnlock()
+	for _, favorite := range fr.favorites {
+		if favorite.UserID == userID && favorite.Quote == quote {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (fr *FavoriteRepository) Reassign(ctx context.Context, fromUserID string, toUserID string) error {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	for i := range fr.favorites {
+		if fr.favorites[i].UserID == fromUserID {
+			fr.favorites[i].UserID = toUserID
+		}
+	}
+	return nil
+}
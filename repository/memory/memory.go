@@ -0,0 +1,76 @@
+// Package memory implements the repositories in process memory. Nothing is
+// persisted, which makes it suitable for local development and tests.
+package memory
+
+import (
+	"sort"
+
+	"dailyscoop-backend/config"
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+func New(cfg config.MemoryConfig) repository.Repository {
+	diaries := NewDiaryRepository()
+	return repository.Repository{
+		Users:          NewUserRepository(),
+		Sessions:       NewSessionRepository(),
+		Diaries:        diaries,
+		Revisions:      NewRevisionRepository(),
+		Favorites:      NewFavoriteRepository(),
+		Emotions:       NewEmotionRepository(cfg.Emotions...),
+		CustomEmotions: NewCustomEmotionRepository(),
+		Themes:         NewThemeRepository(cfg.Themes...),
+		Stats:          NewStatsRepository(diaries),
+		Reports:        NewReportRepository(),
+	}
+}
+
+func cloneDiary(diary model.Diary) model.Diary {
+	diary.Emotions = append([]string(nil), diary.Emotions...)
+	diary.Terms = append([]string(nil), diary.Terms...)
+	if diary.TermCounts != nil {
+		counts := make(map[string]int, len(diary.TermCounts))
+		for term, n := range diary.TermCounts {
+			counts[term] = n
+		}
+		diary.TermCounts = counts
+	}
+	if diary.DeletedAt != nil {
+		deletedAt := *diary.DeletedAt
+		diary.DeletedAt = &deletedAt
+	}
+	return diary
+}
+
+func cloneRevision(revision model.DiaryRevision) model.DiaryRevision {
+	revision.Emotions = append([]string(nil), revision.Emotions...)
+	return revision
+}
+
+// sortDiariesByID orders diaries by date and then by ID, so that pages are
+// stable when several diaries share a date.
+func sortDiariesByID(diaries []model.Diary, order int) {
+	sort.SliceStable(diaries, func(i, j int) bool {
+		a, b := diaries[i], diaries[j]
+		if !a.Date.Equal(b.Date) {
+			if order < 0 {
+				return a.Date.After(b.Date)
+			}
+			return a.Date.Before(b.Date)
+		}
+		if order < 0 {
+			return a.ID > b.ID
+		}
+		return a.ID < b.ID
+	})
+}
+
+func sortDiaries(diaries []model.Diary, order int) {
+	sort.SliceStable(diaries, func(i, j int) bool {
+		if order < 0 {
+			return diaries[i].Date.After(diaries[j].Date)
+		}
+		return diaries[i].Date.Before(diaries[j].Date)
+	})
+}
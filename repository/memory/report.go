@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+type ReportRepository struct {
+	mu      sync.RWMutex
+	reports []model.YearlyReport
+}
+
+func NewReportRepository() *ReportRepository {
+	return &ReportRepository{}
+}
+
+func (rr *ReportRepository) FindYearly(ctx context.Context, userID string, year int) (model.YearlyReport, error) {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+	if i := rr.index(userID, year); i >= 0 {
+		return cloneReport(rr.reports[i]), nil
+	}
+	return model.YearlyReport{}, repository.ErrNotFound
+}
+
+func (rr *ReportRepository) MarkChanged(ctx context.Context, userID string, year int) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	i := rr.index(userID, year)
+	if i < 0 {
+		rr.reports = append(rr.reports, model.YearlyReport{UserID: userID, Year: year})
+		i = len(rr.reports) - 1
+	}
+	rr.reports[i].Changes++
+	return nil
+}
+
+func (rr *ReportRepository) MarkAllChanged(ctx context.Context, userID string) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	for i := range rr.reports {
+		if rr.reports[i].UserID == userID {
+			rr.reports[i].Changes++
+		}
+	}
+	return nil
+}
+
+func (rr *ReportRepository) SaveYearly(ctx context.Context, report model.YearlyReport) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	i := rr.index(report.UserID, report.Year)
+	if i < 0 {
+		rr.reports = append(rr.reports, model.YearlyReport{UserID: report.UserID, Year: report.Year})
+		i = len(rr.reports) - 1
+	}
+	report = cloneReport(report)
+	report.Changes = rr.reports[i].Changes
+	rr.reports[i] = report
+	return nil
+}
+
+func (rr *ReportRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	reports := rr.reports[:0]
+	for _, report := range rr.reports {
+		if report.UserID != userID {
+			reports = append(reports, report)
+		}
+	}
+	rr.reports = reports
+	return nil
+}
+
+func (rr *ReportRepository) index(userID string, year int) int {
+	for i, report := range rr.reports {
+		if report.UserID == userID && report.Year == year {
+			return i
+		}
+	}
+	return -1
+}
+
+func cloneReport(report model.YearlyReport) model.YearlyReport {
+	report.Stats.TopEmotions = append([]model.NameCount(nil), report.Stats.TopEmotions...)
+	report.Stats.TopThemes = append([]model.NameCount(nil), report.Stats.TopThemes...)
+	report.Stats.Highlights = append([]model.Highlight(nil), report.Stats.Highlights...)
+	return report
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+type RevisionRepository struct {
+	mu        sync.RWMutex
+	revisions []model.DiaryRevision
+}
+
+func NewRevisionRepository() *RevisionRepository {
+	return &RevisionRepository{}
+}
+
+func (rr *RevisionRepository) Insert(ctx context.Context, revision model.DiaryRevision) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.revisions = append(rr.revisions, cloneRevision(revision))
+	return nil
+}
+
+func (rr *RevisionRepository) FindByDiaryID(ctx context.Context, userID string, diaryID string) ([]model.DiaryRevision, error) {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+	var revisions []model.DiaryRevision
+	for _, revision := range rr.revisions {
+		if revision.UserID == userID && revision.DiaryID == diaryID {
+			revisions = append(revisions, cloneRevision(revision))
+		}
+	}
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].CreatedAt.Before(revisions[j].CreatedAt)
+	})
+	return revisions, nil
+}
+
+func (rr *RevisionRepository) FindByID(ctx context.Context, userID string, diaryID string, id string) (model.DiaryRevision, error) {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+	for _, revision := range rr.revisions {
+		if revision.UserID == userID && revision.DiaryID == diaryID && revision.ID == id {
+			return cloneRevision(revision), nil
+		}
+	}
+	return model.DiaryRevision{}, repository.ErrNotFound
+}
+
+func (rr *RevisionRepository) CountByDiaryID(ctx context.Context, userID string, diaryID string) (int64, error) {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+	var count int64
+	for _, revision := range rr.revisions {
+		if revision.UserID == userID && revision.DiaryID == diaryID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (rr *RevisionRepository) DeleteByDiaryID(ctx context.Context, userID string, diaryID string) error {
+	return rr.delete(func(revision model.DiaryRevision) bool {
+		return revision.UserID == userID && revision.DiaryID == diaryID
+	})
+}
+
+func (rr *RevisionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return rr.delete(func(revision model.DiaryRevision) bool {
+		return revision.UserID == userID
+	})
+}
+
+func (rr *RevisionRepository) delete(match func(revision model.DiaryRevision) bool) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	revisions := rr.revisions[:0]
+	for _, revision := range rr.revisions {
+		if !match(revision) {
+			revisions = append(revisions, revision)
+		}
+	}
+	rr.revisions = revisions
+	return nil
+}
+
+func (rr *RevisionRepository) Reassign(ctx context.Context, fromUserID string, toUserID string) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	for i := range rr.revisions {
+		if rr.revisions[i].UserID == fromUserID {
+			rr.revisions[i].UserID = toUserID
+		}
+	}
+	return nil
+}
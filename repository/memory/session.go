@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+type SessionRepository struct {
+	mu       sync.RWMutex
+	sessions []model.Session
+}
+
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{}
+}
+
+func (sr *SessionRepository) Insert(ctx context.Context, session model.Session) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.prune(time.Now())
+	sr.sessions = append(sr.sessions, session)
+	return nil
+}
+
+func (sr *SessionRepository) FindByID(ctx context.Context, id string) (model.Session, error) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.prune(time.Now())
+	for _, session := range sr.sessions {
+		if session.ID == id {
+			return session, nil
+		}
+	}
+	return model.Session{}, repository.ErrNotFound
+}
+
+func (sr *SessionRepository) FindByUserID(ctx context.Context, userID string) ([]model.Session, error) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.prune(time.Now())
+	var sessions []model.Session
+	for _, session := range sr.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+func (sr *SessionRepository) Rotate(ctx context.Context, id string, oldHash string, newHash string, seenAt time.Time, expiresAt time.Time) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	for i, session := range sr.sessions {
+		if session.ID == id && session.TokenHash == oldHash {
+			sr.sessions[i].TokenHash = newHash
+			sr.sessions[i].LastSeenAt = seenAt
+			sr.sessions[i].ExpiresAt = expiresAt
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (sr *SessionRepository) Touch(ctx context.Context, id string, seenAt time.Time) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	for i, session := range sr.sessions {
+		if session.ID == id {
+			sr.sessions[i].LastSeenAt = seenAt
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (sr *SessionRepository) Delete(ctx context.Context, userID string, id string) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	for i, session := range sr.sessions {
+		if session.UserID == userID && session.ID == id {
+			sr.sessions = append(sr.sessions[:i], sr.sessions[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (sr *SessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sessions := sr.sessions[:0]
+	for _, session := range sr.sessions {
+		if session.UserID != userID {
+			sessions = append(sessions, session)
+		}
+	}
+	sr.sessions = sessions
+	return nil
+}
+
+// prune deletes the sessions that expired, like the TTL index of the MongoDB
+// backend does.
+func (sr *SessionRepository) prune(now time.Time) {
+	sessions := sr.sessions[:0]
+	for _, session := range sr.sessions {
+		if !session.Expired(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sr.sessions = sessions
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+// TestSessionsExpire checks that expired sessions are gone, as they are from
+// MongoDB once its TTL index removes them.
+func TestSessionsExpire(t *testing.T) {
+	ctx := context.Background()
+	sr := NewSessionRepository()
+	now := time.Now()
+	for _, session := range []model.Session{
+		{ID: "expired", UserID: "user", ExpiresAt: now.Add(-time.Minute)},
+		{ID: "active", UserID: "user", ExpiresAt: now.Add(time.Hour)},
+	} {
+		if err := sr.Insert(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := sr.FindByID(ctx, "expired"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("FindByID(expired): got %v, want ErrNotFound", err)
+	}
+	sessions, err := sr.FindByUserID(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "active" {
+		t.Errorf("got sessions %+v, want only the active one", sessions)
+	}
+	if len(sr.sessions) != 1 {
+		t.Errorf("%d sessions are stored, want 1", len(sr.sessions))
+	}
+}
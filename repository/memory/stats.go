@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/period"
+	"dailyscoop-backend/repository"
+)
+
+// StatsRepository aggregates the diaries of a DiaryRepository.
+type StatsRepository struct {
+	diaries *DiaryRepository
+}
+
+func NewStatsRepository(diaries *DiaryRepository) *StatsRepository {
+	return &StatsRepository{diaries: diaries}
+}
+
+func (sr *StatsRepository) CountDiaries(ctx context.Context, userID string, from, to time.Time, loc *time.Location) (repository.DiaryCount, error) {
+	days, err := sr.CountByDay(ctx, userID, from, to, loc)
+	if err != nil {
+		return repository.DiaryCount{}, err
+	}
+	count := repository.DiaryCount{Days: int64(len(days))}
+	for _, day := range days {
+		count.Entries += int64(day.Count)
+	}
+	return count, nil
+}
+
+func (sr *StatsRepository) CountByDay(ctx context.Context, userID string, from, to time.Time, loc *time.Location) ([]repository.DayCount, error) {
+	var counts []repository.DayCount
+	for _, diary := range sr.inRange(userID, from, to) {
+		t := diary.Date.In(loc)
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		if n := len(counts); n > 0 && counts[n-1].Date.Equal(day) {
+			counts[n-1].Count++
+			continue
+		}
+		counts = append(counts, repository.DayCount{Date: day, Count: 1})
+	}
+	return counts, nil
+}
+
+func (sr *StatsRepository) CountEmotions(ctx context.Context, userID string, from, to time.Time) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, diary := range sr.inRange(userID, from, to) {
+		for _, emotion := range diary.Emotions {
+			counts[emotion]++
+		}
+	}
+	return counts, nil
+}
+
+func (sr *StatsRepository) CountEmotionsByPeriod(ctx context.Context, userID string, from, to time.Time, unit period.Unit, weekStart time.Weekday, loc *time.Location) ([]repository.EmotionCount, error) {
+	type key struct {
+		start   int64
+		emotion string
+	}
+	counts := make(map[key]*repository.EmotionCount)
+	for _, diary := range sr.inRange(userID, from, to) {
+		p, err := period.Of(unit, diary.Date.In(loc), weekStart)
+		if err != nil {
+			return nil, err
+		}
+		for _, emotion := range diary.Emotions {
+			k := key{p.Start.Unix(), emotion}
+			if counts[k] == nil {
+				counts[k] = &repository.EmotionCount{Start: p.Start, Emotion: emotion}
+			}
+			counts[k].Count++
+		}
+	}
+	result := make([]repository.EmotionCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Start.Equal(result[j].Start) {
+			return result[i].Start.Before(result[j].Start)
+		}
+		return result[i].Emotion < result[j].Emotion
+	})
+	return result, nil
+}
+
+func (sr *StatsRepository) CountThemes(ctx context.Context, userID string, from, to time.Time) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, diary := range sr.inRange(userID, from, to) {
+		counts[diary.Theme]++
+	}
+	return counts, nil
+}
+
+func (sr *StatsRepository) CountEmotionPairs(ctx context.Context, userID string, from, to time.Time) ([]repository.EmotionPairCount, error) {
+	type key struct{ a, b string }
+	counts := make(map[key]int)
+	for _, diary := range sr.inRange(userID, from, to) {
+		emotions := distinct(diary.Emotions)
+		for i, a := range emotions {
+			for _, b := range emotions[i+1:] {
+				counts[key{a, b}]++
+			}
+		}
+	}
+	result := make([]repository.EmotionPairCount, 0, len(counts))
+	for k, count := range counts {
+		result = append(result, repository.EmotionPairCount{A: k.a, B: k.b, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].A != result[j].A {
+			return result[i].A < result[j].A
+		}
+		return result[i].B < result[j].B
+	})
+	return result, nil
+}
+
+func (sr *StatsRepository) CountEmotionsByWeekday(ctx context.Context, userID string, from, to time.Time, loc *time.Location) ([]repository.WeekdayEmotionCount, error) {
+	type key struct {
+		weekday time.Weekday
+		emotion string
+	}
+	counts := make(map[key]int)
+	for _, diary := range sr.inRange(userID, from, to) {
+		for _, emotion := range diary.Emotions {
+			counts[key{diary.Date.In(loc).Weekday(), emotion}]++
+		}
+	}
+	result := make([]repository.WeekdayEmotionCount, 0, len(counts))
+	for k, count := range counts {
+		result = append(result, repository.WeekdayEmotionCount{Weekday: k.weekday, Emotion: k.emotion, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Weekday != result[j].Weekday {
+			return result[i].Weekday < result[j].Weekday
+		}
+		return result[i].Emotion < result[j].Emotion
+	})
+	return result, nil
+}
+
+func (sr *StatsRepository) CountEmotionsByTheme(ctx context.Context, userID string, from, to time.Time) ([]repository.ThemeEmotionCount, error) {
+	type key struct{ theme, emotion string }
+	counts := make(map[key]int)
+	for _, diary := range sr.inRange(userID, from, to) {
+		for _, emotion := range diary.Emotions {
+			counts[key{diary.Theme, emotion}]++
+		}
+	}
+	result := make([]repository.ThemeEmotionCount, 0, len(counts))
+	for k, count := range counts {
+		result = append(result, repository.ThemeEmotionCount{Theme: k.theme, Emotion: k.emotion, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Theme != result[j].Theme {
+			return result[i].Theme < result[j].Theme
+		}
+		return result[i].Emotion < result[j].Emotion
+	})
+	return result, nil
+}
+
+// distinct returns the sorted distinct values.
+func distinct(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	var result []string
+	for _, v := range values {
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// inRange returns the diaries of the user in the range, oldest first.
+func (sr *StatsRepository) inRange(userID string, from, to time.Time) []model.Diary {
+	return sr.diaries.find(func(diary model.Diary) bool {
+		return diary.UserID == userID && inRange(diary.Date, from, to)
+	}, 1)
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/period"
+)
+
+var benchEmotions = []string{"기쁨", "슬픔", "화남", "평온", "불안", "설렘"}
+
+// seedDecade writes two diaries a day for ten years ending in 2025 and
+// returns the repository with the location they were written in.
+func seedDecade(b *testing.B) (*StatsRepository, *time.Location) {
+	b.Helper()
+	loc, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		b.Fatal(err)
+	}
+	diaries := NewDiaryRepository()
+	ctx := context.Background()
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, loc)
+	end := time.Date(2026, 1, 1, 0, 0, 0, 0, loc)
+	n := 0
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		for _, hour := range []int{9, 21} {
+			if err := diaries.Insert(ctx, model.Diary{
+				ID:       fmt.Sprintf("diary-%d", n),
+				UserID:   "user",
+				Content:  "오늘은 좋은 하루였다",
+				Date:     day.Add(time.Duration(hour) * time.Hour),
+				Emotions: []string{benchEmotions[n%len(benchEmotions)], benchEmotions[(n/2)%len(benchEmotions)]},
+				Theme:    "basic",
+			}); err != nil {
+				b.Fatal(err)
+			}
+			n++
+		}
+	}
+	return NewStatsRepository(diaries), loc
+}
+
+func BenchmarkCountDiariesYear(b *testing.B) {
+	sr, loc := seedDecade(b)
+	ctx := context.Background()
+	from, to := time.Date(2025, 1, 1, 0, 0, 0, 0, loc), time.Date(2026, 1, 1, 0, 0, 0, 0, loc)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sr.CountDiaries(ctx, "user", from, to, loc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCountEmotionsYear(b *testing.B) {
+	sr, loc := seedDecade(b)
+	ctx := context.Background()
+	from, to := time.Date(2025, 1, 1, 0, 0, 0, 0, loc), time.Date(2026, 1, 1, 0, 0, 0, 0, loc)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sr.CountEmotions(ctx, "user", from, to); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCountEmotionsByPeriodYear(b *testing.B) {
+	sr, loc := seedDecade(b)
+	ctx := context.Background()
+	from, to := time.Date(2025, 1, 1, 0, 0, 0, 0, loc), time.Date(2026, 1, 1, 0, 0, 0, 0, loc)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sr.CountEmotionsByPeriod(ctx, "user", from, to, period.Weekly, time.Monday, loc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCountEmotionPairs(t *testing.T) {
+	diaries := NewDiaryRepository()
+	ctx := context.Background()
+	day := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	for i, emotions := range [][]string{
+		{"기쁨", "설렘"},
+		{"설렘", "기쁨", "기쁨"},
+		{"기쁨", "불안", "설렘"},
+		{"슬픔"},
+	} {
+		if err := diaries.Insert(ctx, model.Diary{
+			ID:       fmt.Sprint(i),
+			UserID:   "user",
+			Date:     day.AddDate(0, 0, i),
+			Emotions: emotions,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sr := NewStatsRepository(diaries)
+	pairs, err := sr.CountEmotionPairs(ctx, "user", day, day.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[[2]string]int{
+		{"기쁨", "불안"}: 1,
+		{"기쁨", "설렘"}: 3,
+		{"불안", "설렘"}: 1,
+	}
+	if len(pairs) != len(want) {
+		t.Fatalf("CountEmotionPairs returned %d pairs, want %d: %v", len(pairs), len(want), pairs)
+	}
+	for _, pair := range pairs {
+		if pair.A >= pair.B {
+			t.Errorf("pair %q, %q is not ordered", pair.A, pair.B)
+		}
+		if got := want[[2]string{pair.A, pair.B}]; pair.Count != got {
+			t.Errorf("pair %q, %q counts %d, want %d", pair.A, pair.B, pair.Count, got)
+		}
+	}
+}
+
+func TestCountEmotionsByWeekday(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diaries := NewDiaryRepository()
+	ctx := context.Background()
+	// Tuesday 23:30 in UTC is already Wednesday in Seoul.
+	date := time.Date(2024, 5, 14, 23, 30, 0, 0, time.UTC)
+	if err := diaries.Insert(ctx, model.Diary{ID: "1", UserID: "user", Date: date, Emotions: []string{"기쁨"}}); err != nil {
+		t.Fatal(err)
+	}
+	sr := NewStatsRepository(diaries)
+	counts, err := sr.CountEmotionsByWeekday(ctx, "user", date.AddDate(0, 0, -1), date.AddDate(0, 0, 1), loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 1 || counts[0].Weekday != time.Wednesday || counts[0].Count != 1 {
+		t.Errorf("CountEmotionsByWeekday = %v, want one diary on Wednesday", counts)
+	}
+}
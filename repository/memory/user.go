@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+type UserRepository struct {
+	mu    sync.RWMutex
+	users map[string]model.User
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users: make(map[string]model.User),
+	}
+}
+
+func (ur *UserRepository) FindByID(ctx context.Context, id string) (model.User, error) {
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+	user, ok := ur.users[id]
+	if !ok {
+		return model.User{}, repository.ErrNotFound
+	}
+	return cloneUser(user), nil
+}
+
+func (ur *UserRepository) FindByNickname(ctx context.Context, nickname string) (model.User, error) {
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+	for _, user := range ur.users {
+		if user.Nickname == nickname {
+			return cloneUser(user), nil
+		}
+	}
+	return model.User{}, repository.ErrNotFound
+}
+
+func (ur *UserRepository) Insert(ctx context.Context, user model.User) error {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	if _, ok := ur.users[user.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	for _, identity := range user.Identities {
+		if ur.linked(identity) {
+			return repository.ErrDuplicate
+		}
+	}
+	ur.users[user.ID] = cloneUser(user)
+	return nil
+}
+
+func (ur *UserRepository) Delete(ctx context.Context, id string) error {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	delete(ur.users, id)
+	return nil
+}
+
+func (ur *UserRepository) UpdateNickname(ctx context.Context, id string, nickname string) error {
+	return ur.update(id, func(user *model.User) {
+		user.Nickname = nickname
+	})
+}
+
+func (ur *UserRepository) UpdatePassword(ctx context.Context, id string, password string) error {
+	return ur.update(id, func(user *model.User) {
+		user.Password = password
+	})
+}
+
+func (ur *UserRepository) UpdateProfileImage(ctx context.Context, id string, image string) error {
+	return ur.update(id, func(user *model.User) {
+		user.ProfileImage = image
+	})
+}
+
+func (ur *UserRepository) UpdateMultipleEntries(ctx context.Context, id string, multipleEntries bool) error {
+	return ur.update(id, func(user *model.User) {
+		user.MultipleEntries = multipleEntries
+	})
+}
+
+func (ur *UserRepository) UpdateTimezone(ctx context.Context, id string, timezone string) error {
+	return ur.update(id, func(user *model.User) {
+		user.Timezone = timezone
+	})
+}
+
+func (ur *UserRepository) IncrementTokenVersion(ctx context.Context, id string) error {
+	return ur.update(id, func(user *model.User) {
+		user.TokenVersion++
+	})
+}
+
+func (ur *UserRepository) FindByIdentity(ctx context.Context, provider string, subject string) (model.User, error) {
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+	for _, user := range ur.users {
+		if identity, ok := user.Identity(provider); ok && identity.Subject == subject {
+			return cloneUser(user), nil
+		}
+	}
+	return model.User{}, repository.ErrNotFound
+}
+
+func (ur *UserRepository) AddIdentity(ctx context.Context, id string, identity model.Identity) error {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	user, ok := ur.users[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if ur.linked(identity) {
+		return repository.ErrDuplicate
+	}
+	user.Identities = append(user.Identities, identity)
+	ur.users[id] = user
+	return nil
+}
+
+func (ur *UserRepository) MoveIdentities(ctx context.Context, fromID string, toID string) error {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	from, ok := ur.users[fromID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	to, ok := ur.users[toID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	to.Identities = append(to.Identities, from.Identities...)
+	from.Identities = nil
+	ur.users[fromID] = from
+	ur.users[toID] = to
+	return nil
+}
+
+func (ur *UserRepository) RemoveIdentity(ctx context.Context, id string, provider string) error {
+	return ur.update(id, func(user *model.User) {
+		identities := user.Identities[:0]
+		for _, identity := range user.Identities {
+			if identity.Provider != provider {
+				identities = append(identities, identity)
+			}
+		}
+		user.Identities = identities
+	})
+}
+
+// linked reports whether a user has the identity, like the unique index of the
+// MongoDB backend does.
+func (ur *UserRepository) linked(identity model.Identity) bool {
+	for _, user := range ur.users {
+		for _, linked := range user.Identities {
+			if linked.Provider == identity.Provider && linked.Subject == identity.Subject {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (ur *UserRepository) update(id string, fn func(user *model.User)) error {
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+	user, ok := ur.users[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	fn(&user)
+	ur.users[id] = user
+	return nil
+}
+
+func cloneUser(user model.User) model.User {
+	user.Identities = append([]model.Identity(nil), user.Identities...)
+	user.PendingIdentities = append([]model.Identity(nil), user.PendingIdentities...)
+	return user
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+// TestUserRepositoryErrors checks that the repository fails like the MongoDB
+// one does.
+func TestUserRepositoryErrors(t *testing.T) {
+	ctx := context.Background()
+	ur := NewUserRepository()
+	if err := ur.Insert(ctx, model.User{ID: "u1", Nickname: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ur.Insert(ctx, model.User{ID: "u1", Nickname: "second"}); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("inserting an existing ID: got %v, want ErrDuplicate", err)
+	}
+	if user, err := ur.FindByID(ctx, "u1"); err != nil || user.Nickname != "first" {
+		t.Errorf("FindByID = %q, %v; want first", user.Nickname, err)
+	}
+	if err := ur.UpdateNickname(ctx, "u2", "missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("updating a missing user: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestUserRepositoryLinksIdentitiesOnce(t *testing.T) {
+	ctx := context.Background()
+	ur := NewUserRepository()
+	kakao := model.Identity{Provider: "kakao", Subject: "k1"}
+	if err := ur.Insert(ctx, model.User{ID: "u1", Identities: []model.Identity{kakao}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ur.Insert(ctx, model.User{ID: "u2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ur.AddIdentity(ctx, "u2", kakao); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("linking an identity of another user: got %v, want ErrDuplicate", err)
+	}
+	if err := ur.Insert(ctx, model.User{ID: "u3", Identities: []model.Identity{kakao}}); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("inserting a user with a linked identity: got %v, want ErrDuplicate", err)
+	}
+	// The same subject at another provider is another identity.
+	if err := ur.AddIdentity(ctx, "u2", model.Identity{Provider: "google", Subject: "k1"}); err != nil {
+		t.Fatal(err)
+	}
+	if user, err := ur.FindByIdentity(ctx, "kakao", "k1"); err != nil || user.ID != "u1" {
+		t.Errorf("FindByIdentity(kakao, k1) = %q, %v; want u1", user.ID, err)
+	}
+}
+
+func TestUserRepositoryMoveIdentities(t *testing.T) {
+	ctx := context.Background()
+	ur := NewUserRepository()
+	kakao := model.Identity{Provider: "kakao", Subject: "k1"}
+	if err := ur.Insert(ctx, model.User{ID: "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ur.Insert(ctx, model.User{ID: "u2", Identities: []model.Identity{kakao}}); err != nil {
+		t.Fatal(err)
+	}
+	// Moving again, as a retried merge does, changes nothing.
+	for i := 0; i < 2; i++ {
+		if err := ur.MoveIdentities(ctx, "u2", "u1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if user, err := ur.FindByIdentity(ctx, "kakao", "k1"); err != nil || user.ID != "u1" {
+		t.Errorf("FindByIdentity(kakao, k1) = %q, %v; want u1", user.ID, err)
+	}
+	if user, err := ur.FindByID(ctx, "u1"); err != nil || len(user.Identities) != 1 {
+		t.Errorf("u1 has identities %v, %v; want one", user.Identities, err)
+	}
+	if err := ur.MoveIdentities(ctx, "u2", "u3"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("moving to a missing user: got %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,151 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+type EmotionRepository struct {
+	db *mongo.Database
+}
+
+func (er *EmotionRepository) FindAll(ctx context.Context) ([]model.Emotion, error) {
+	coll := er.db.Collection("emotions")
+	cursor, err := coll.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{
+		{Key: model.EmotionOrderKey, Value: 1},
+		{Key: model.EmotionNameKey, Value: 1},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var emotions []model.Emotion
+	for cursor.Next(ctx) {
+		var emotion model.Emotion
+		if err := cursor.Decode(&emotion); err != nil {
+			return nil, err
+		}
+		emotions = append(emotions, emotion)
+	}
+	return emotions, cursor.Err()
+}
+
+func (er *EmotionRepository) FindByName(ctx context.Context, name string) (model.Emotion, error) {
+	coll := er.db.Collection("emotions")
+	var emotion model.Emotion
+	if err := coll.FindOne(ctx, bson.M{model.EmotionNameKey: name}).Decode(&emotion); err != nil {
+		return model.Emotion{}, translateError(err)
+	}
+	return emotion, nil
+}
+
+func (er *EmotionRepository) Exists(ctx context.Context, name string) (bool, error) {
+	return exists(ctx, er.db.Collection("emotions"), bson.M{
+		model.EmotionNameKey: name,
+	})
+}
+
+func (er *EmotionRepository) Insert(ctx context.Context, emotion model.Emotion) error {
+	coll := er.db.Collection("emotions")
+	if _, err := coll.InsertOne(ctx, emotion); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+func (er *EmotionRepository) Update(ctx context.Context, emotion model.Emotion) error {
+	coll := er.db.Collection("emotions")
+	result, err := coll.UpdateOne(ctx, bson.M{model.EmotionNameKey: emotion.Name}, bson.M{
+		"$set": bson.M{
+			model.EmotionEmojiKey:   emotion.Emoji,
+			model.EmotionColorKey:   emotion.Color,
+			model.EmotionValenceKey: emotion.Valence,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (er *EmotionRepository) Retire(ctx context.Context, name string, at time.Time) error {
+	coll := er.db.Collection("emotions")
+	result, err := coll.UpdateOne(ctx, bson.M{model.EmotionNameKey: name}, bson.M{
+		"$set": bson.M{model.EmotionRetiredAtKey: at},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (er *EmotionRepository) Reorder(ctx context.Context, names []string) error {
+	coll := er.db.Collection("emotions")
+	models := make([]mongo.WriteModel, 0, len(names))
+	for order, name := range names {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{model.EmotionNameKey: name}).
+			SetUpdate(bson.M{"$set": bson.M{model.EmotionOrderKey: order}}))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	if _, err := coll.BulkWrite(ctx, models); err != nil {
+		return err
+	}
+	return nil
+}
+
+type ThemeRepository struct {
+	db *mongo.Database
+}
+
+func (tr *ThemeRepository) FindAll(ctx context.Context) ([]model.Theme, error) {
+	coll := tr.db.Collection("themes")
+	cursor, err := coll.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{
+		{Key: model.ThemeNameKey, Value: 1},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var themes []model.Theme
+	for cursor.Next(ctx) {
+		var theme model.Theme
+		if err := cursor.Decode(&theme); err != nil {
+			return nil, err
+		}
+		themes = append(themes, theme)
+	}
+	return themes, cursor.Err()
+}
+
+func (tr *ThemeRepository) Exists(ctx context.Context, name string) (bool, error) {
+	return exists(ctx, tr.db.Collection("themes"), bson.M{
+		model.ThemeNameKey: name,
+	})
+}
+
+func exists(ctx context.Context, coll *mongo.Collection, filter bson.M) (bool, error) {
+	if err := coll.FindOne(ctx, filter).Err(); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,83 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dailyscoop-backend/model"
+)
+
+type CustomEmotionRepository struct {
+	db *mongo.Database
+}
+
+func (cr *CustomEmotionRepository) Insert(ctx context.Context, emotion model.CustomEmotion) error {
+	coll := cr.db.Collection("custom_emotions")
+	if _, err := coll.InsertOne(ctx, emotion); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cr *CustomEmotionRepository) FindByUserID(ctx context.Context, userID string) ([]model.CustomEmotion, error) {
+	coll := cr.db.Collection("custom_emotions")
+	cursor, err := coll.Find(ctx, bson.M{
+		model.CustomEmotionUserIDKey: userID,
+	}, options.Find().SetSort(bson.D{{Key: model.CustomEmotionCreatedAtKey, Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var emotions []model.CustomEmotion
+	for cursor.Next(ctx) {
+		var emotion model.CustomEmotion
+		if err := cursor.Decode(&emotion); err != nil {
+			return nil, err
+		}
+		emotions = append(emotions, emotion)
+	}
+	return emotions, cursor.Err()
+}
+
+func (cr *CustomEmotionRepository) Exists(ctx context.Context, userID string, name string) (bool, error) {
+	return exists(ctx, cr.db.Collection("custom_emotions"), bson.M{
+		model.CustomEmotionUserIDKey: userID,
+		model.CustomEmotionNameKey:   name,
+	})
+}
+
+func (cr *CustomEmotionRepository) Delete(ctx context.Context, userID string, name string) error {
+	coll := cr.db.Collection("custom_emotions")
+	if _, err := coll.DeleteOne(ctx, bson.M{
+		model.CustomEmotionUserIDKey: userID,
+		model.CustomEmotionNameKey:   name,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cr *CustomEmotionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	coll := cr.db.Collection("custom_emotions")
+	if _, err := coll.DeleteMany(ctx, bson.M{
+		model.CustomEmotionUserIDKey: userID,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cr *CustomEmotionRepository) Reassign(ctx context.Context, fromUserID string, toUserID string) error {
+	coll := cr.db.Collection("custom_emotions")
+	if _, err := coll.UpdateMany(ctx, bson.M{
+		model.CustomEmotionUserIDKey: fromUserID,
+	}, bson.M{
+		"$set": bson.M{model.CustomEmotionUserIDKey: toUserID},
+	}); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,395 @@
+package mongodb
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+type DiaryRepository struct {
+	db *mongo.Database
+}
+
+func (dr *DiaryRepository) Find(ctx context.Context, query repository.DiaryQuery) ([]model.Diary, error) {
+	sort := 1
+	if query.Sort < 0 {
+		sort = -1
+	}
+	option := options.Find().SetSort(bson.D{
+		{Key: model.DiaryDateKey, Value: sort},
+		{Key: model.DiaryIDKey, Value: sort},
+	})
+	if query.Limit > 0 {
+		option.SetLimit(int64(query.Limit))
+	}
+	if len(query.Fields) > 0 {
+		projection := bson.M{
+			model.DiaryIDKey:   1,
+			model.DiaryDateKey: 1,
+		}
+		for _, field := range query.Fields {
+			projection[field] = 1
+		}
+		option.SetProjection(projection)
+	}
+	return dr.findWithOptions(ctx, diaryFilter(query), option)
+}
+
+func (dr *DiaryRepository) FindMatches(ctx context.Context, query repository.DiaryQuery, phrase string) ([]repository.DiaryMatch, error) {
+	query.After = nil
+	sort := 1
+	if query.Sort < 0 {
+		sort = -1
+	}
+	projection := bson.M{
+		model.DiaryIDKey:         1,
+		model.DiaryTermLengthKey: 1,
+	}
+	for _, term := range query.Terms {
+		projection[model.DiaryTermCountsKey+"."+term] = 1
+	}
+	if words := strings.Fields(phrase); len(words) > 0 {
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		projection["phrase"] = bson.M{"$regexMatch": bson.M{
+			"input":   bson.M{"$ifNull": bson.A{"$" + model.DiaryContentKey, ""}},
+			"regex":   strings.Join(words, `\s+`),
+			"options": "i",
+		}}
+	}
+	coll := dr.db.Collection("diaries")
+	cursor, err := coll.Find(ctx, diaryFilter(query), options.Find().SetSort(bson.D{
+		{Key: model.DiaryDateKey, Value: sort},
+		{Key: model.DiaryIDKey, Value: sort},
+	}).SetProjection(projection))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var matches []repository.DiaryMatch
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID         string         `bson:"id"`
+			TermCounts map[string]int `bson:"term_counts"`
+			TermLength int            `bson:"term_length"`
+			Phrase     bool           `bson:"phrase"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		matches = append(matches, repository.DiaryMatch{
+			ID:         doc.ID,
+			TermCounts: doc.TermCounts,
+			TermLength: doc.TermLength,
+			Phrase:     doc.Phrase,
+		})
+	}
+	return matches, cursor.Err()
+}
+
+// diaryFilter selects the diaries out of the trash that the query selects.
+func diaryFilter(query repository.DiaryQuery) bson.M {
+	filter := bson.M{
+		model.DiaryUserIDKey: query.UserID,
+	}
+	if len(query.IDs) > 0 {
+		filter[model.DiaryIDKey] = bson.M{"$in": query.IDs}
+	}
+	if len(query.Terms) > 0 {
+		filter[model.DiaryTermsKey] = bson.M{"$all": query.Terms}
+	}
+	if len(query.Emotions) > 0 {
+		if query.AllEmotions {
+			filter[model.DiaryEmotionsKey] = bson.M{"$all": query.Emotions}
+		} else {
+			filter[model.DiaryEmotionsKey] = bson.M{"$in": query.Emotions}
+		}
+	}
+	if query.Theme != "" {
+		filter[model.DiaryThemeKey] = query.Theme
+	}
+	if !query.From.IsZero() || !query.To.IsZero() {
+		date := bson.M{}
+		if !query.From.IsZero() {
+			date["$gte"] = query.From
+		}
+		if !query.To.IsZero() {
+			date["$lt"] = query.To
+		}
+		filter[model.DiaryDateKey] = date
+	}
+	if query.HasImage != nil {
+		if *query.HasImage {
+			filter[model.DiaryImageKey] = bson.M{"$nin": bson.A{"", nil}}
+		} else {
+			filter[model.DiaryImageKey] = bson.M{"$in": bson.A{"", nil}}
+		}
+	}
+	if query.MinLength > 0 {
+		filter["$expr"] = bson.M{"$gte": bson.A{
+			bson.M{"$strLenCP": bson.M{"$ifNull": bson.A{"$" + model.DiaryContentKey, ""}}},
+			query.MinLength,
+		}}
+	}
+	if query.After != nil {
+		op := "$gt"
+		if query.Sort < 0 {
+			op = "$lt"
+		}
+		filter["$or"] = bson.A{
+			bson.M{model.DiaryDateKey: bson.M{op: query.After.Date}},
+			bson.M{
+				model.DiaryDateKey: query.After.Date,
+				model.DiaryIDKey:   bson.M{op: query.After.ID},
+			},
+		}
+	}
+	return live(filter)
+}
+
+func (dr *DiaryRepository) FindInRange(ctx context.Context, userID string, from, to time.Time, sort int) ([]model.Diary, error) {
+	return dr.find(ctx, live(bson.M{
+		model.DiaryUserIDKey: userID,
+		model.DiaryDateKey:   dateRange(from, to),
+	}), sort)
+}
+
+func (dr *DiaryRepository) FindOneInRange(ctx context.Context, userID string, from, to time.Time) (model.Diary, error) {
+	return dr.findOne(ctx, live(bson.M{
+		model.DiaryUserIDKey: userID,
+		model.DiaryDateKey:   dateRange(from, to),
+	}))
+}
+
+func (dr *DiaryRepository) FindByID(ctx context.Context, userID string, id string) (model.Diary, error) {
+	return dr.findOne(ctx, live(bson.M{
+		model.DiaryUserIDKey: userID,
+		model.DiaryIDKey:     id,
+	}))
+}
+
+func (dr *DiaryRepository) Insert(ctx context.Context, diary model.Diary) error {
+	coll := dr.db.Collection("diaries")
+	if _, err := coll.InsertOne(ctx, diary); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dr *DiaryRepository) UpsertInRange(ctx context.Context, diary model.Diary, from, to time.Time) (model.Diary, error) {
+	coll := dr.db.Collection("diaries")
+	var stored model.Diary
+	if err := coll.FindOneAndUpdate(ctx, live(bson.M{
+		model.DiaryDateKey:   dateRange(from, to),
+		model.DiaryUserIDKey: diary.UserID,
+	}), bson.M{
+		"$set": bson.M{
+			model.DiaryContentKey:      diary.Content,
+			model.DiaryImageKey:        diary.Image,
+			model.DiaryEmotionsKey:     diary.Emotions,
+			model.DiaryThemeKey:        diary.Theme,
+			model.DiaryTermsKey:        diary.Terms,
+			model.DiaryTermsVersionKey: diary.TermsVersion,
+			model.DiaryTermCountsKey:   diary.TermCounts,
+			model.DiaryTermLengthKey:   diary.TermLength,
+		},
+		"$setOnInsert": bson.M{
+			model.DiaryIDKey:   diary.ID,
+			model.DiaryDateKey: diary.Date,
+		},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&stored); err != nil {
+		return model.Diary{}, err
+	}
+	return stored, nil
+}
+
+func (dr *DiaryRepository) Update(ctx context.Context, diary model.Diary) error {
+	return dr.updateOne(ctx, live(bson.M{
+		model.DiaryUserIDKey: diary.UserID,
+		model.DiaryIDKey:     diary.ID,
+	}), bson.M{
+		"$set": bson.M{
+			model.DiaryContentKey:      diary.Content,
+			model.DiaryImageKey:        diary.Image,
+			model.DiaryDateKey:         diary.Date,
+			model.DiaryEmotionsKey:     diary.Emotions,
+			model.DiaryThemeKey:        diary.Theme,
+			model.DiaryTermsKey:        diary.Terms,
+			model.DiaryTermsVersionKey: diary.TermsVersion,
+			model.DiaryTermCountsKey:   diary.TermCounts,
+			model.DiaryTermLengthKey:   diary.TermLength,
+		},
+	})
+}
+
+func (dr *DiaryRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	coll := dr.db.Collection("diaries")
+	if _, err := coll.DeleteMany(ctx, bson.M{
+		model.DiaryUserIDKey: userID,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dr *DiaryRepository) Trash(ctx context.Context, userID string, id string, at time.Time) error {
+	return dr.updateOne(ctx, live(bson.M{
+		model.DiaryUserIDKey: userID,
+		model.DiaryIDKey:     id,
+	}), bson.M{
+		"$set": bson.M{
+			model.DiaryDeletedAtKey: at,
+		},
+	})
+}
+
+func (dr *DiaryRepository) FindTrashed(ctx context.Context, userID string) ([]model.Diary, error) {
+	return dr.findWithOptions(ctx, trashed(bson.M{
+		model.DiaryUserIDKey: userID,
+	}), options.Find().SetSort(bson.M{
+		model.DiaryDeletedAtKey: -1,
+	}))
+}
+
+func (dr *DiaryRepository) FindTrashedByID(ctx context.Context, userID string, id string) (model.Diary, error) {
+	return dr.findOne(ctx, trashed(bson.M{
+		model.DiaryUserIDKey: userID,
+		model.DiaryIDKey:     id,
+	}))
+}
+
+func (dr *DiaryRepository) Restore(ctx context.Context, userID string, id string) error {
+	return dr.updateOne(ctx, trashed(bson.M{
+		model.DiaryUserIDKey: userID,
+		model.DiaryIDKey:     id,
+	}), bson.M{
+		"$unset": bson.M{
+			model.DiaryDeletedAtKey: "",
+		},
+	})
+}
+
+func (dr *DiaryRepository) DeleteTrashed(ctx context.Context, userID string, id string) error {
+	coll := dr.db.Collection("diaries")
+	result, err := coll.DeleteOne(ctx, trashed(bson.M{
+		model.DiaryUserIDKey: userID,
+		model.DiaryIDKey:     id,
+	}))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (dr *DiaryRepository) PurgeTrashed(ctx context.Context, before time.Time) ([]model.Diary, error) {
+	coll := dr.db.Collection("diaries")
+	filter := bson.M{
+		model.DiaryDeletedAtKey: bson.M{"$lt": before},
+	}
+	diaries, err := dr.findWithOptions(ctx, filter, options.Find())
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, diary := range diaries {
+		ids = append(ids, diary.ID)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if _, err := coll.DeleteMany(ctx, bson.M{
+		model.DiaryIDKey:        bson.M{"$in": ids},
+		model.DiaryDeletedAtKey: bson.M{"$lt": before},
+	}); err != nil {
+		return nil, err
+	}
+	return diaries, nil
+}
+
+func (dr *DiaryRepository) find(ctx context.Context, filter bson.M, sort int) ([]model.Diary, error) {
+	return dr.findWithOptions(ctx, filter, options.Find().SetSort(bson.M{
+		model.DiaryDateKey: sort,
+	}))
+}
+
+func (dr *DiaryRepository) findWithOptions(ctx context.Context, filter bson.M, option *options.FindOptions) ([]model.Diary, error) {
+	coll := dr.db.Collection("diaries")
+	cursor, err := coll.Find(ctx, filter, option)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var diaries []model.Diary
+	for cursor.Next(ctx) {
+		var diary model.Diary
+		if err := cursor.Decode(&diary); err != nil {
+			return nil, err
+		}
+		diaries = append(diaries, diary)
+	}
+	return diaries, nil
+}
+
+func (dr *DiaryRepository) findOne(ctx context.Context, filter bson.M) (model.Diary, error) {
+	coll := dr.db.Collection("diaries")
+	var diary model.Diary
+	if err := coll.FindOne(ctx, filter).Decode(&diary); err != nil {
+		return model.Diary{}, translateError(err)
+	}
+	return diary, nil
+}
+
+func (dr *DiaryRepository) updateOne(ctx context.Context, filter bson.M, update bson.M) error {
+	coll := dr.db.Collection("diaries")
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func dateRange(from, to time.Time) bson.M {
+	return bson.M{
+		"$gte": from,
+		"$lt":  to,
+	}
+}
+
+// live restricts filter to diaries that are not in the trash. A nil value
+// matches both a missing and a null field.
+func live(filter bson.M) bson.M {
+	filter[model.DiaryDeletedAtKey] = nil
+	return filter
+}
+
+func trashed(filter bson.M) bson.M {
+	filter[model.DiaryDeletedAtKey] = bson.M{"$ne": nil}
+	return filter
+}
+
+func (dr *DiaryRepository) Reassign(ctx context.Context, fromUserID string, toUserID string) error {
+	coll := dr.db.Collection("diaries")
+	if _, err := coll.UpdateMany(ctx, bson.M{
+		model.DiaryUserIDKey: fromUserID,
+	}, bson.M{
+		"$set": bson.M{model.DiaryUserIDKey: toUserID},
+	}); err != nil {
+		return err
+	}
+	return nil
+}
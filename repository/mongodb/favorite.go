@@ -0,0 +1,82 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"dailyscoop-backend/model"
+)
+
+type FavoriteRepository struct {
+	db *mongo.Database
+}
+
+func (fr *FavoriteRepository) Insert(ctx context.Context, favorite model.Favorite) error {
+	coll := fr.db.Collection("favorites")
+	if _, err := coll.InsertOne(ctx, favorite); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (fr *FavoriteRepository) FindByUserID(ctx context.Context, userID string) ([]model.Favorite, error) {
+	coll := fr.db.Collection("favorites")
+	cursor, err := coll.Find(ctx, bson.M{
+		model.FavoriteUserIDKey: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var favorites []model.Favorite
+	for cursor.Next(ctx) {
+		var favorite model.Favorite
+		if err := cursor.Decode(&favorite); err != nil {
+			return nil, err
+		}
+		favorites = append(favorites, favorite)
+	}
+	return favorites, nil
+}
+
+func (fr *FavoriteRepository) Delete(ctx context.Context, userID string, quote string) error {
+	coll := fr.db.Collection("favorites")
+	if _, err := coll.DeleteOne(ctx, bson.M{
+		model.FavoriteUserIDKey:  userID,
+		model.FavoriteContentKey: quote,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (fr *FavoriteRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	coll := fr.db.Collection("favorites")
+	if _, err := coll.DeleteMany(ctx, bson.M{
+		model.FavoriteUserIDKey: userID,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (fr *FavoriteRepository) Exists(ctx context.Context, userID string, quote string) (bool, error) {
+	return exists(ctx, fr.db.Collection("favorites"), bson.M{
+		model.FavoriteUserIDKey:  userID,
+		model.FavoriteContentKey: quote,
+	})
+}
+
+func (fr *FavoriteRepository) Reassign(ctx context.Context, fromUserID string, toUserID string) error {
+	coll := fr.db.Collection("favorites")
+	if _, err := coll.UpdateMany(ctx, bson.M{
+		model.FavoriteUserIDKey: fromUserID,
+	}, bson.M{
+		"$set": bson.M{model.FavoriteUserIDKey: toUserID},
+	}); err != nil {
+		return err
+	}
+	return nil
+}
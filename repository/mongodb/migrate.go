@@ -0,0 +1,180 @@
+package mongodb
+
+import (
+	"context"
+
+	uuid "github.com/satori/go.uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dailyscoop-backend/config"
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/search"
+)
+
+// Migrate brings documents written by older versions of the server up to date
+// and creates the indexes the repositories rely on. It is safe to run on
+// every start.
+func Migrate(ctx context.Context, cfg config.MongoConfig, mc *mongo.Client) error {
+	db := mc.Database(cfg.Database)
+	if err := backfillDiaryIDs(ctx, db); err != nil {
+		return err
+	}
+	if err := backfillDiaryTerms(ctx, db); err != nil {
+		return err
+	}
+	if _, err := db.Collection("diaries").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: model.DiaryIDKey, Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: model.DiaryUserIDKey, Value: 1},
+				{Key: model.DiaryTermsKey, Value: 1},
+			},
+		},
+		// Listings, calendars and statistics all match a user and a date
+		// range.
+		{
+			Keys: bson.D{
+				{Key: model.DiaryUserIDKey, Value: 1},
+				{Key: model.DiaryDateKey, Value: 1},
+			},
+		},
+	}); err != nil {
+		return err
+	}
+	// Revisions are looked up by diary and listed in the order they were
+	// written.
+	if _, err := db.Collection("revisions").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: model.DiaryRevisionUserIDKey, Value: 1},
+			{Key: model.DiaryRevisionDiaryIDKey, Value: 1},
+			{Key: model.DiaryRevisionCreatedAtKey, Value: 1},
+		},
+	}); err != nil {
+		return err
+	}
+	if _, err := db.Collection("users").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: model.UserIDKey, Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// Users are looked up by identity on every social login, and an
+		// identity signs in to one user only. Users without identities are
+		// left out, or they would all share the same missing key.
+		{
+			Keys: bson.D{
+				{Key: model.UserIdentitiesKey + "." + model.IdentityProviderKey, Value: 1},
+				{Key: model.UserIdentitiesKey + "." + model.IdentitySubjectKey, Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				model.UserIdentitiesKey + "." + model.IdentitySubjectKey: bson.M{"$exists": true},
+			}),
+		},
+	}); err != nil {
+		return err
+	}
+	// Expired sessions are removed by MongoDB itself.
+	if _, err := db.Collection("sessions").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: model.SessionIDKey, Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: model.SessionUserIDKey, Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: model.SessionExpiresAtKey, Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}); err != nil {
+		return err
+	}
+	if _, err := db.Collection("emotions").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: model.EmotionNameKey, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return err
+	}
+	if _, err := db.Collection("custom_emotions").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: model.CustomEmotionUserIDKey, Value: 1},
+			{Key: model.CustomEmotionNameKey, Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return err
+	}
+	if _, err := db.Collection("reports").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: model.YearlyReportUserIDKey, Value: 1},
+			{Key: model.YearlyReportYearKey, Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func backfillDiaryIDs(ctx context.Context, db *mongo.Database) error {
+	coll := db.Collection("diaries")
+	cursor, err := coll.Find(ctx, bson.M{
+		model.DiaryIDKey: bson.M{"$exists": false},
+	}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ObjectID interface{} `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		if _, err := coll.UpdateOne(ctx, bson.M{"_id": doc.ObjectID}, bson.M{
+			"$set": bson.M{model.DiaryIDKey: uuid.NewV4().String()},
+		}); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// backfillDiaryTerms indexes the diaries whose terms are missing or were
+// built by an older version of package search.
+func backfillDiaryTerms(ctx context.Context, db *mongo.Database) error {
+	coll := db.Collection("diaries")
+	cursor, err := coll.Find(ctx, bson.M{
+		model.DiaryTermsVersionKey: bson.M{"$ne": search.Version},
+	}, options.Find().SetProjection(bson.M{model.DiaryContentKey: 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ObjectID interface{} `bson:"_id"`
+			Content  string
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		counts, length := search.Counts(doc.Content)
+		if _, err := coll.UpdateOne(ctx, bson.M{"_id": doc.ObjectID}, bson.M{
+			"$set": bson.M{
+				model.DiaryTermsKey:        search.Terms(doc.Content),
+				model.DiaryTermsVersionKey: search.Version,
+				model.DiaryTermCountsKey:   counts,
+				model.DiaryTermLengthKey:   length,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
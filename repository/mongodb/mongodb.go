@@ -0,0 +1,36 @@
+package mongodb
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"dailyscoop-backend/config"
+	"dailyscoop-backend/repository"
+)
+
+func New(cfg config.MongoConfig, mc *mongo.Client) repository.Repository {
+	db := mc.Database(cfg.Database)
+	return repository.Repository{
+		Users:          &UserRepository{db: db},
+		Sessions:       &SessionRepository{db: db},
+		Diaries:        &DiaryRepository{db: db},
+		Revisions:      &RevisionRepository{db: db},
+		Favorites:      &FavoriteRepository{db: db},
+		Emotions:       &EmotionRepository{db: db},
+		CustomEmotions: &CustomEmotionRepository{db: db},
+		Themes:         &ThemeRepository{db: db},
+		Stats:          &StatsRepository{db: db},
+		Reports:        &ReportRepository{db: db},
+	}
+}
+
+func translateError(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return repository.ErrNotFound
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return repository.ErrDuplicate
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dailyscoop-backend/model"
+)
+
+type ReportRepository struct {
+	db *mongo.Database
+}
+
+func (rr *ReportRepository) FindYearly(ctx context.Context, userID string, year int) (model.YearlyReport, error) {
+	coll := rr.db.Collection("reports")
+	var report model.YearlyReport
+	if err := coll.FindOne(ctx, bson.M{
+		model.YearlyReportUserIDKey: userID,
+		model.YearlyReportYearKey:   year,
+	}).Decode(&report); err != nil {
+		return model.YearlyReport{}, translateError(err)
+	}
+	return report, nil
+}
+
+func (rr *ReportRepository) MarkChanged(ctx context.Context, userID string, year int) error {
+	coll := rr.db.Collection("reports")
+	if _, err := coll.UpdateOne(ctx, bson.M{
+		model.YearlyReportUserIDKey: userID,
+		model.YearlyReportYearKey:   year,
+	}, bson.M{
+		"$inc": bson.M{model.YearlyReportChangesKey: 1},
+	}, options.Update().SetUpsert(true)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *ReportRepository) MarkAllChanged(ctx context.Context, userID string) error {
+	coll := rr.db.Collection("reports")
+	if _, err := coll.UpdateMany(ctx, bson.M{
+		model.YearlyReportUserIDKey: userID,
+	}, bson.M{
+		"$inc": bson.M{model.YearlyReportChangesKey: 1},
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *ReportRepository) SaveYearly(ctx context.Context, report model.YearlyReport) error {
+	coll := rr.db.Collection("reports")
+	if _, err := coll.UpdateOne(ctx, bson.M{
+		model.YearlyReportUserIDKey: report.UserID,
+		model.YearlyReportYearKey:   report.Year,
+	}, bson.M{
+		"$set": bson.M{
+			model.YearlyReportGeneratedChangesKey: report.GeneratedChanges,
+			model.YearlyReportGeneratedAtKey:      report.GeneratedAt,
+			model.YearlyReportStatsKey:            report.Stats,
+		},
+	}, options.Update().SetUpsert(true)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *ReportRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	coll := rr.db.Collection("reports")
+	if _, err := coll.DeleteMany(ctx, bson.M{
+		model.YearlyReportUserIDKey: userID,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dailyscoop-backend/model"
+)
+
+type RevisionRepository struct {
+	db *mongo.Database
+}
+
+func (rr *RevisionRepository) Insert(ctx context.Context, revision model.DiaryRevision) error {
+	coll := rr.db.Collection("revisions")
+	if _, err := coll.InsertOne(ctx, revision); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *RevisionRepository) FindByDiaryID(ctx context.Context, userID string, diaryID string) ([]model.DiaryRevision, error) {
+	coll := rr.db.Collection("revisions")
+	option := options.Find().SetSort(bson.M{
+		model.DiaryRevisionCreatedAtKey: 1,
+	})
+	cursor, err := coll.Find(ctx, bson.M{
+		model.DiaryRevisionUserIDKey:  userID,
+		model.DiaryRevisionDiaryIDKey: diaryID,
+	}, option)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var revisions []model.DiaryRevision
+	for cursor.Next(ctx) {
+		var revision model.DiaryRevision
+		if err := cursor.Decode(&revision); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+func (rr *RevisionRepository) FindByID(ctx context.Context, userID string, diaryID string, id string) (model.DiaryRevision, error) {
+	coll := rr.db.Collection("revisions")
+	var revision model.DiaryRevision
+	if err := coll.FindOne(ctx, bson.M{
+		model.DiaryRevisionUserIDKey:  userID,
+		model.DiaryRevisionDiaryIDKey: diaryID,
+		model.DiaryRevisionIDKey:      id,
+	}).Decode(&revision); err != nil {
+		return model.DiaryRevision{}, translateError(err)
+	}
+	return revision, nil
+}
+
+func (rr *RevisionRepository) CountByDiaryID(ctx context.Context, userID string, diaryID string) (int64, error) {
+	coll := rr.db.Collection("revisions")
+	return coll.CountDocuments(ctx, bson.M{
+		model.DiaryRevisionUserIDKey:  userID,
+		model.DiaryRevisionDiaryIDKey: diaryID,
+	})
+}
+
+func (rr *RevisionRepository) DeleteByDiaryID(ctx context.Context, userID string, diaryID string) error {
+	coll := rr.db.Collection("revisions")
+	if _, err := coll.DeleteMany(ctx, bson.M{
+		model.DiaryRevisionUserIDKey:  userID,
+		model.DiaryRevisionDiaryIDKey: diaryID,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *RevisionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	coll := rr.db.Collection("revisions")
+	if _, err := coll.DeleteMany(ctx, bson.M{
+		model.DiaryRevisionUserIDKey: userID,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rr *RevisionRepository) Reassign(ctx context.Context, fromUserID string, toUserID string) error {
+	coll := rr.db.Collection("revisions")
+	if _, err := coll.UpdateMany(ctx, bson.M{
+		model.DiaryRevisionUserIDKey: fromUserID,
+	}, bson.M{
+		"$set": bson.M{model.DiaryRevisionUserIDKey: toUserID},
+	}); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+type SessionRepository struct {
+	db *mongo.Database
+}
+
+func (sr *SessionRepository) Insert(ctx context.Context, session model.Session) error {
+	coll := sr.db.Collection("sessions")
+	if _, err := coll.InsertOne(ctx, session); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (sr *SessionRepository) FindByID(ctx context.Context, id string) (model.Session, error) {
+	coll := sr.db.Collection("sessions")
+	var session model.Session
+	if err := coll.FindOne(ctx, bson.M{model.SessionIDKey: id}).Decode(&session); err != nil {
+		return model.Session{}, translateError(err)
+	}
+	return session, nil
+}
+
+func (sr *SessionRepository) FindByUserID(ctx context.Context, userID string) ([]model.Session, error) {
+	coll := sr.db.Collection("sessions")
+	cursor, err := coll.Find(ctx, bson.M{
+		model.SessionUserIDKey: userID,
+	}, options.Find().SetSort(bson.D{{Key: model.SessionLastSeenAtKey, Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var sessions []model.Session
+	for cursor.Next(ctx) {
+		var session model.Session
+		if err := cursor.Decode(&session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, cursor.Err()
+}
+
+func (sr *SessionRepository) Rotate(ctx context.Context, id string, oldHash string, newHash string, seenAt time.Time, expiresAt time.Time) error {
+	coll := sr.db.Collection("sessions")
+	result, err := coll.UpdateOne(ctx, bson.M{
+		model.SessionIDKey:        id,
+		model.SessionTokenHashKey: oldHash,
+	}, bson.M{
+		"$set": bson.M{
+			model.SessionTokenHashKey:  newHash,
+			model.SessionLastSeenAtKey: seenAt,
+			model.SessionExpiresAtKey:  expiresAt,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (sr *SessionRepository) Touch(ctx context.Context, id string, seenAt time.Time) error {
+	coll := sr.db.Collection("sessions")
+	result, err := coll.UpdateOne(ctx, bson.M{
+		model.SessionIDKey: id,
+	}, bson.M{
+		"$set": bson.M{model.SessionLastSeenAtKey: seenAt},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (sr *SessionRepository) Delete(ctx context.Context, userID string, id string) error {
+	coll := sr.db.Collection("sessions")
+	result, err := coll.DeleteOne(ctx, bson.M{
+		model.SessionUserIDKey: userID,
+		model.SessionIDKey:     id,
+	})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (sr *SessionRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	coll := sr.db.Collection("sessions")
+	if _, err := coll.DeleteMany(ctx, bson.M{
+		model.SessionUserIDKey: userID,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,352 @@
+package mongodb
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/period"
+	"dailyscoop-backend/repository"
+)
+
+type StatsRepository struct {
+	db *mongo.Database
+}
+
+func (sr *StatsRepository) CountDiaries(ctx context.Context, userID string, from, to time.Time, loc *time.Location) (repository.DiaryCount, error) {
+	coll := sr.db.Collection("diaries")
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: live(bson.M{
+			model.DiaryUserIDKey: userID,
+			model.DiaryDateKey:   dateRange(from, to),
+		})}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   formatDay(loc),
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     nil,
+			"entries": bson.M{"$sum": "$count"},
+			"days":    bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return repository.DiaryCount{}, err
+	}
+	defer cursor.Close(ctx)
+	var result struct {
+		Entries int64
+		Days    int64
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return repository.DiaryCount{}, err
+		}
+	}
+	return repository.DiaryCount{
+		Entries: result.Entries,
+		Days:    result.Days,
+	}, cursor.Err()
+}
+
+func (sr *StatsRepository) CountByDay(ctx context.Context, userID string, from, to time.Time, loc *time.Location) ([]repository.DayCount, error) {
+	coll := sr.db.Collection("diaries")
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: live(bson.M{
+			model.DiaryUserIDKey: userID,
+			model.DiaryDateKey:   dateRange(from, to),
+		})}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   formatDay(loc),
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var counts []repository.DayCount
+	for cursor.Next(ctx) {
+		var result struct {
+			Day   string `bson:"_id"`
+			Count int
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		date, err := time.ParseInLocation("2006-01-02", result.Day, loc)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, repository.DayCount{Date: date, Count: result.Count})
+	}
+	return counts, cursor.Err()
+}
+
+func (sr *StatsRepository) CountEmotions(ctx context.Context, userID string, from, to time.Time) (map[string]int, error) {
+	coll := sr.db.Collection("diaries")
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: live(bson.M{
+			model.DiaryUserIDKey: userID,
+			model.DiaryDateKey:   dateRange(from, to),
+		})}},
+		{{Key: "$unwind", Value: "$" + model.DiaryEmotionsKey}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$" + model.DiaryEmotionsKey,
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	counts := make(map[string]int)
+	for cursor.Next(ctx) {
+		var result struct {
+			Emotion string `bson:"_id"`
+			Count   int
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		counts[result.Emotion] = result.Count
+	}
+	return counts, cursor.Err()
+}
+
+func (sr *StatsRepository) CountEmotionsByPeriod(ctx context.Context, userID string, from, to time.Time, unit period.Unit, weekStart time.Weekday, loc *time.Location) ([]repository.EmotionCount, error) {
+	coll := sr.db.Collection("diaries")
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: live(bson.M{
+			model.DiaryUserIDKey: userID,
+			model.DiaryDateKey:   dateRange(from, to),
+		})}},
+		{{Key: "$unwind", Value: "$" + model.DiaryEmotionsKey}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"start":   truncateDate(unit, weekStart, loc),
+				"emotion": "$" + model.DiaryEmotionsKey,
+			},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.start", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var counts []repository.EmotionCount
+	for cursor.Next(ctx) {
+		var result struct {
+			ID struct {
+				Start   time.Time
+				Emotion string
+			} `bson:"_id"`
+			Count int
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		counts = append(counts, repository.EmotionCount{
+			Start:   result.ID.Start,
+			Emotion: result.ID.Emotion,
+			Count:   result.Count,
+		})
+	}
+	return counts, cursor.Err()
+}
+
+func (sr *StatsRepository) CountThemes(ctx context.Context, userID string, from, to time.Time) (map[string]int, error) {
+	coll := sr.db.Collection("diaries")
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: live(bson.M{
+			model.DiaryUserIDKey: userID,
+			model.DiaryDateKey:   dateRange(from, to),
+		})}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$" + model.DiaryThemeKey,
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	counts := make(map[string]int)
+	for cursor.Next(ctx) {
+		var result struct {
+			Theme string `bson:"_id"`
+			Count int
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		counts[result.Theme] = result.Count
+	}
+	return counts, cursor.Err()
+}
+
+func (sr *StatsRepository) CountEmotionPairs(ctx context.Context, userID string, from, to time.Time) ([]repository.EmotionPairCount, error) {
+	coll := sr.db.Collection("diaries")
+	// Pairing the distinct emotions of a diary with themselves and keeping
+	// the ordered pairs counts each pair once per diary.
+	emotions := bson.M{"$setUnion": bson.A{"$" + model.DiaryEmotionsKey, bson.A{}}}
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: live(bson.M{
+			model.DiaryUserIDKey: userID,
+			model.DiaryDateKey:   dateRange(from, to),
+		})}},
+		{{Key: "$project", Value: bson.M{"a": emotions, "b": emotions}}},
+		{{Key: "$unwind", Value: "$a"}},
+		{{Key: "$unwind", Value: "$b"}},
+		{{Key: "$match", Value: bson.M{"$expr": bson.M{"$lt": bson.A{"$a", "$b"}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"a": "$a", "b": "$b"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.a", Value: 1}, {Key: "_id.b", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var counts []repository.EmotionPairCount
+	for cursor.Next(ctx) {
+		var result struct {
+			ID struct {
+				A string
+				B string
+			} `bson:"_id"`
+			Count int
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		counts = append(counts, repository.EmotionPairCount{
+			A:     result.ID.A,
+			B:     result.ID.B,
+			Count: result.Count,
+		})
+	}
+	return counts, cursor.Err()
+}
+
+func (sr *StatsRepository) CountEmotionsByWeekday(ctx context.Context, userID string, from, to time.Time, loc *time.Location) ([]repository.WeekdayEmotionCount, error) {
+	coll := sr.db.Collection("diaries")
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: live(bson.M{
+			model.DiaryUserIDKey: userID,
+			model.DiaryDateKey:   dateRange(from, to),
+		})}},
+		{{Key: "$unwind", Value: "$" + model.DiaryEmotionsKey}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"weekday": bson.M{"$dayOfWeek": bson.M{
+					"date":     "$" + model.DiaryDateKey,
+					"timezone": loc.String(),
+				}},
+				"emotion": "$" + model.DiaryEmotionsKey,
+			},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.weekday", Value: 1}, {Key: "_id.emotion", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var counts []repository.WeekdayEmotionCount
+	for cursor.Next(ctx) {
+		var result struct {
+			ID struct {
+				// Weekday runs from 1 for Sunday to 7 for Saturday.
+				Weekday int
+				Emotion string
+			} `bson:"_id"`
+			Count int
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		counts = append(counts, repository.WeekdayEmotionCount{
+			Weekday: time.Weekday(result.ID.Weekday - 1),
+			Emotion: result.ID.Emotion,
+			Count:   result.Count,
+		})
+	}
+	return counts, cursor.Err()
+}
+
+func (sr *StatsRepository) CountEmotionsByTheme(ctx context.Context, userID string, from, to time.Time) ([]repository.ThemeEmotionCount, error) {
+	coll := sr.db.Collection("diaries")
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: live(bson.M{
+			model.DiaryUserIDKey: userID,
+			model.DiaryDateKey:   dateRange(from, to),
+		})}},
+		{{Key: "$unwind", Value: "$" + model.DiaryEmotionsKey}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"theme":   "$" + model.DiaryThemeKey,
+				"emotion": "$" + model.DiaryEmotionsKey,
+			},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.theme", Value: 1}, {Key: "_id.emotion", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var counts []repository.ThemeEmotionCount
+	for cursor.Next(ctx) {
+		var result struct {
+			ID struct {
+				Theme   string
+				Emotion string
+			} `bson:"_id"`
+			Count int
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		counts = append(counts, repository.ThemeEmotionCount{
+			Theme:   result.ID.Theme,
+			Emotion: result.ID.Emotion,
+			Count:   result.Count,
+		})
+	}
+	return counts, cursor.Err()
+}
+
+// truncateDate is the expression for the start of the period of unit that
+// contains the diary date.
+func truncateDate(unit period.Unit, weekStart time.Weekday, loc *time.Location) bson.M {
+	units := map[period.Unit]string{
+		period.Daily:     "day",
+		period.Weekly:    "week",
+		period.Monthly:   "month",
+		period.Quarterly: "quarter",
+		period.Yearly:    "year",
+	}
+	return bson.M{"$dateTrunc": bson.M{
+		"date":        "$" + model.DiaryDateKey,
+		"unit":        units[unit],
+		"timezone":    loc.String(),
+		"startOfWeek": strings.ToLower(weekStart.String()),
+	}}
+}
+
+// formatDay is the expression for the day of the diary date in loc.
+func formatDay(loc *time.Location) bson.M {
+	return bson.M{"$dateToString": bson.M{
+		"format":   "%Y-%m-%d",
+		"date":     "$" + model.DiaryDateKey,
+		"timezone": loc.String(),
+	}}
+}
@@ -0,0 +1,107 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dailyscoop-backend/config"
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/period"
+)
+
+var benchEmotions = []string{"기쁨", "슬픔", "화남", "평온", "불안", "설렘"}
+
+// seedDecade writes two diaries a day for ten years ending in 2025 into a
+// scratch database of the MongoDB at DAILYSCOOP_MONGO_URL, and skips the
+// benchmark when it is not set. The database is dropped afterwards.
+func seedDecade(b *testing.B) (*StatsRepository, *time.Location) {
+	b.Helper()
+	url := os.Getenv("DAILYSCOOP_MONGO_URL")
+	if url == "" {
+		b.Skip("DAILYSCOOP_MONGO_URL is not set")
+	}
+	loc, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+	mc, err := mongo.Connect(ctx, options.Client().ApplyURI(url))
+	if err != nil {
+		b.Fatal(err)
+	}
+	cfg := config.MongoConfig{URL: url, Database: "dailyscoop_bench"}
+	db := mc.Database(cfg.Database)
+	b.Cleanup(func() {
+		db.Drop(ctx)
+		mc.Disconnect(ctx)
+	})
+	if err := db.Drop(ctx); err != nil {
+		b.Fatal(err)
+	}
+	if err := Migrate(ctx, cfg, mc); err != nil {
+		b.Fatal(err)
+	}
+	var docs []interface{}
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, loc)
+	end := time.Date(2026, 1, 1, 0, 0, 0, 0, loc)
+	n := 0
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		for _, hour := range []int{9, 21} {
+			docs = append(docs, model.Diary{
+				ID:       fmt.Sprintf("diary-%d", n),
+				UserID:   "user",
+				Content:  "오늘은 좋은 하루였다",
+				Date:     day.Add(time.Duration(hour) * time.Hour),
+				Emotions: []string{benchEmotions[n%len(benchEmotions)], benchEmotions[(n/2)%len(benchEmotions)]},
+				Theme:    "basic",
+			})
+			n++
+		}
+	}
+	if _, err := db.Collection("diaries").InsertMany(ctx, docs); err != nil {
+		b.Fatal(err)
+	}
+	return &StatsRepository{db: db}, loc
+}
+
+func BenchmarkCountDiariesYear(b *testing.B) {
+	sr, loc := seedDecade(b)
+	ctx := context.Background()
+	from, to := time.Date(2025, 1, 1, 0, 0, 0, 0, loc), time.Date(2026, 1, 1, 0, 0, 0, 0, loc)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sr.CountDiaries(ctx, "user", from, to, loc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCountEmotionsYear(b *testing.B) {
+	sr, loc := seedDecade(b)
+	ctx := context.Background()
+	from, to := time.Date(2025, 1, 1, 0, 0, 0, 0, loc), time.Date(2026, 1, 1, 0, 0, 0, 0, loc)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sr.CountEmotions(ctx, "user", from, to); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCountEmotionsByPeriodYear(b *testing.B) {
+	sr, loc := seedDecade(b)
+	ctx := context.Background()
+	from, to := time.Date(2025, 1, 1, 0, 0, 0, 0, loc), time.Date(2026, 1, 1, 0, 0, 0, 0, loc)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sr.CountEmotionsByPeriod(ctx, "user", from, to, period.Weekly, time.Monday, loc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
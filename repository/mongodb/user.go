@@ -0,0 +1,200 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+type UserRepository struct {
+	db *mongo.Database
+}
+
+func (ur *UserRepository) FindByID(ctx context.Context, id string) (model.User, error) {
+	coll := ur.db.Collection("users")
+	var user model.User
+	if err := coll.FindOne(ctx, bson.M{model.UserIDKey: id}).Decode(&user); err != nil {
+		return model.User{}, translateError(err)
+	}
+	return user, nil
+}
+
+func (ur *UserRepository) FindByNickname(ctx context.Context, nickname string) (model.User, error) {
+	coll := ur.db.Collection("users")
+	var user model.User
+	if err := coll.FindOne(ctx, bson.M{model.UserNicknameKey: nickname}).Decode(&user); err != nil {
+		return model.User{}, translateError(err)
+	}
+	return user, nil
+}
+
+func (ur *UserRepository) Insert(ctx context.Context, user model.User) error {
+	coll := ur.db.Collection("users")
+	if _, err := coll.InsertOne(ctx, user); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+func (ur *UserRepository) Delete(ctx context.Context, id string) error {
+	coll := ur.db.Collection("users")
+	if _, err := coll.DeleteOne(ctx, bson.M{
+		model.UserIDKey: id,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ur *UserRepository) UpdateNickname(ctx context.Context, id string, nickname string) error {
+	return ur.set(ctx, id, bson.M{model.UserNicknameKey: nickname})
+}
+
+func (ur *UserRepository) UpdatePassword(ctx context.Context, id string, password string) error {
+	return ur.set(ctx, id, bson.M{model.UserPasswordKey: password})
+}
+
+func (ur *UserRepository) UpdateProfileImage(ctx context.Context, id string, image string) error {
+	return ur.set(ctx, id, bson.M{model.UserProfileImageKey: image})
+}
+
+func (ur *UserRepository) UpdateMultipleEntries(ctx context.Context, id string, multipleEntries bool) error {
+	return ur.set(ctx, id, bson.M{model.UserMultipleEntriesKey: multipleEntries})
+}
+
+func (ur *UserRepository) UpdateTimezone(ctx context.Context, id string, timezone string) error {
+	return ur.set(ctx, id, bson.M{model.UserTimezoneKey: timezone})
+}
+
+func (ur *UserRepository) IncrementTokenVersion(ctx context.Context, id string) error {
+	return ur.update(ctx, id, bson.M{
+		"$inc": bson.M{model.UserTokenVersionKey: 1},
+	})
+}
+
+// FindByIdentity finds the user the identity is linked to, or else the user
+// it is being moved to, whose move it then finishes.
+func (ur *UserRepository) FindByIdentity(ctx context.Context, provider string, subject string) (model.User, error) {
+	user, err := ur.findByIdentity(ctx, model.UserIdentitiesKey, provider, subject)
+	if err == nil || !errors.Is(err, repository.ErrNotFound) {
+		return user, err
+	}
+	user, err = ur.findByIdentity(ctx, model.UserPendingIdentitiesKey, provider, subject)
+	if err != nil {
+		return model.User{}, err
+	}
+	if err := ur.commitPendingIdentities(ctx, user); err != nil {
+		return model.User{}, err
+	}
+	return ur.FindByID(ctx, user.ID)
+}
+
+func (ur *UserRepository) findByIdentity(ctx context.Context, key string, provider string, subject string) (model.User, error) {
+	coll := ur.db.Collection("users")
+	var user model.User
+	if err := coll.FindOne(ctx, bson.M{
+		key: bson.M{
+			"$elemMatch": bson.M{
+				model.IdentityProviderKey: provider,
+				model.IdentitySubjectKey:  subject,
+			},
+		},
+	}).Decode(&user); err != nil {
+		return model.User{}, translateError(err)
+	}
+	return user, nil
+}
+
+func (ur *UserRepository) AddIdentity(ctx context.Context, id string, identity model.Identity) error {
+	// The unique index on identities turns a concurrent link of the same
+	// identity into repository.ErrDuplicate.
+	return ur.update(ctx, id, bson.M{
+		"$push": bson.M{model.UserIdentitiesKey: identity},
+	})
+}
+
+// MoveIdentities moves the identities in steps that can each be repeated,
+// since the unique index keeps an identity from being linked to both users
+// at once: they are added to the pending identities of toID, which
+// FindByIdentity finds too, removed from fromID, and then made identities of
+// toID.
+func (ur *UserRepository) MoveIdentities(ctx context.Context, fromID string, toID string) error {
+	from, err := ur.FindByID(ctx, fromID)
+	if err != nil {
+		return err
+	}
+	if len(from.Identities) > 0 {
+		providers := make([]string, 0, len(from.Identities))
+		for _, identity := range from.Identities {
+			providers = append(providers, identity.Provider)
+		}
+		if err := ur.update(ctx, toID, bson.M{
+			"$addToSet": bson.M{model.UserPendingIdentitiesKey: bson.M{"$each": from.Identities}},
+		}); err != nil {
+			return err
+		}
+		if err := ur.update(ctx, fromID, bson.M{
+			"$pull": bson.M{model.UserIdentitiesKey: bson.M{model.IdentityProviderKey: bson.M{"$in": providers}}},
+		}); err != nil {
+			return err
+		}
+	}
+	to, err := ur.FindByID(ctx, toID)
+	if err != nil {
+		return err
+	}
+	return ur.commitPendingIdentities(ctx, to)
+}
+
+// commitPendingIdentities makes the pending identities of the user its
+// identities, each in a single update.
+func (ur *UserRepository) commitPendingIdentities(ctx context.Context, user model.User) error {
+	coll := ur.db.Collection("users")
+	for _, identity := range user.PendingIdentities {
+		key := bson.M{
+			model.IdentityProviderKey: identity.Provider,
+			model.IdentitySubjectKey:  identity.Subject,
+		}
+		if _, err := coll.UpdateOne(ctx, bson.M{
+			model.UserIDKey:                user.ID,
+			model.UserPendingIdentitiesKey: bson.M{"$elemMatch": key},
+		}, bson.M{
+			"$push": bson.M{model.UserIdentitiesKey: identity},
+			"$pull": bson.M{model.UserPendingIdentitiesKey: key},
+		}); err != nil {
+			return translateError(err)
+		}
+	}
+	return nil
+}
+
+func (ur *UserRepository) RemoveIdentity(ctx context.Context, id string, provider string) error {
+	return ur.update(ctx, id, bson.M{
+		"$pull": bson.M{model.UserIdentitiesKey: bson.M{model.IdentityProviderKey: provider}},
+	})
+}
+
+func (ur *UserRepository) set(ctx context.Context, id string, fields bson.M) error {
+	return ur.update(ctx, id, bson.M{"$set": fields})
+}
+
+// update applies the update to the user, and fails with
+// repository.ErrNotFound if there is no such user.
+func (ur *UserRepository) update(ctx context.Context, id string, update bson.M) error {
+	coll := ur.db.Collection("users")
+	result, err := coll.UpdateOne(ctx, bson.M{
+		model.UserIDKey: id,
+	}, update)
+	if err != nil {
+		return translateError(err)
+	}
+	if result.MatchedCount == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/period"
+)
+
+var (
+	ErrNotFound = errors.New("repository: not found")
+	// ErrDuplicate is returned when a document would take a key another
+	// document already has.
+	ErrDuplicate = errors.New("repository: duplicate")
+)
+
+type Repository struct {
+	Users     UserRepository
+	Sessions  SessionRepository
+	Diaries   DiaryRepository
+	Revisions RevisionRepository
+	Favorites FavoriteRepository
+	Emotions  EmotionRepository
+	// CustomEmotions stores the emotions users made for themselves.
+	CustomEmotions CustomEmotionRepository
+	Themes         ThemeRepository
+	Stats          StatsRepository
+	Reports        ReportRepository
+}
+
+type UserRepository interface {
+	FindByID(ctx context.Context, id string) (model.User, error)
+	FindByNickname(ctx context.Context, nickname string) (model.User, error)
+	// Insert fails with ErrDuplicate when the ID or one of the identities
+	// is taken.
+	Insert(ctx context.Context, user model.User) error
+	Delete(ctx context.Context, id string) error
+	UpdateNickname(ctx context.Context, id string, nickname string) error
+	UpdatePassword(ctx context.Context, id string, password string) error
+	UpdateProfileImage(ctx context.Context, id string, image string) error
+	UpdateMultipleEntries(ctx context.Context, id string, multipleEntries bool) error
+	UpdateTimezone(ctx context.Context, id string, timezone string) error
+	IncrementTokenVersion(ctx context.Context, id string) error
+	FindByIdentity(ctx context.Context, provider string, subject string) (model.User, error)
+	// AddIdentity fails with ErrDuplicate when the identity is linked to a
+	// user already.
+	AddIdentity(ctx context.Context, id string, identity model.Identity) error
+	RemoveIdentity(ctx context.Context, id string, provider string) error
+	// MoveIdentities moves the identities of the user fromID to the user
+	// toID. Each identity signs in to one of them throughout, and calling it
+	// again after it was interrupted finishes the move.
+	MoveIdentities(ctx context.Context, fromID string, toID string) error
+}
+
+// DiaryRepository stores diaries. Ranges are half-open: from is inclusive and
+// to is exclusive. Diaries in the trash are invisible to every method except
+// the trash methods and DeleteByUserID.
+type DiaryRepository interface {
+	Find(ctx context.Context, query DiaryQuery) ([]model.Diary, error)
+	// FindMatches describes how the diaries found by the query match its
+	// Terms, and whether their content contains phrase, without loading the
+	// content. It ignores After, Limit and Fields.
+	FindMatches(ctx context.Context, query DiaryQuery, phrase string) ([]DiaryMatch, error)
+	FindInRange(ctx context.Context, userID string, from, to time.Time, sort int) ([]model.Diary, error)
+	FindOneInRange(ctx context.Context, userID string, from, to time.Time) (model.Diary, error)
+	FindByID(ctx context.Context, userID string, id string) (model.Diary, error)
+	Insert(ctx context.Context, diary model.Diary) error
+	// UpsertInRange updates the diary found in the range or inserts a new one,
+	// and returns the stored diary. An existing diary keeps its ID and date.
+	UpsertInRange(ctx context.Context, diary model.Diary, from, to time.Time) (model.Diary, error)
+	// Update replaces the diary with the same ID and user ID.
+	Update(ctx context.Context, diary model.Diary) error
+	DeleteByUserID(ctx context.Context, userID string) error
+	// Reassign gives the diaries of a user to another, trashed ones
+	// included.
+	Reassign(ctx context.Context, fromUserID string, toUserID string) error
+	Trash(ctx context.Context, userID string, id string, at time.Time) error
+	// FindTrashed returns the diaries in the trash, most recently deleted
+	// first.
+	FindTrashed(ctx context.Context, userID string) ([]model.Diary, error)
+	FindTrashedByID(ctx context.Context, userID string, id string) (model.Diary, error)
+	Restore(ctx context.Context, userID string, id string) error
+	DeleteTrashed(ctx context.Context, userID string, id string) error
+	// PurgeTrashed deletes the diaries of every user that were trashed before
+	// the given time, and returns them.
+	PurgeTrashed(ctx context.Context, before time.Time) ([]model.Diary, error)
+}
+
+// DiaryQuery selects a page of a user's diaries, ordered by date and then by
+// ID in the direction of Sort.
+type DiaryQuery struct {
+	UserID string
+	// IDs, when not empty, limits the diaries to the ones with these IDs.
+	IDs []string
+	// Terms are search terms that all have to be among the diary terms.
+	Terms []string
+	// Emotions the diary has to have: all of them if AllEmotions is set,
+	// otherwise at least one.
+	Emotions    []string
+	AllEmotions bool
+	Theme       string
+	// From and To bound the date when they are not zero. To is exclusive.
+	From time.Time
+	To   time.Time
+	// HasImage, when set, requires the diary to have an image or not.
+	HasImage *bool
+	// MinLength is the minimum length of the content in characters.
+	MinLength int
+	Sort      int
+	// After is the position of the last diary of the previous page.
+	After *DiaryCursor
+	// Limit is the maximum number of diaries returned. Zero means no limit.
+	Limit int
+	// Fields lists the diary keys to load. ID and date are always loaded;
+	// empty means every field.
+	Fields []string
+}
+
+// DiaryMatch holds the search statistics of a diary: how often each query
+// term occurs in it, its length in terms and whether it contains the phrase
+// searched for.
+type DiaryMatch struct {
+	ID         string
+	TermCounts map[string]int
+	TermLength int
+	Phrase     bool
+}
+
+type DiaryCursor struct {
+	Date time.Time
+	ID   string
+}
+
+type RevisionRepository interface {
+	Insert(ctx context.Context, revision model.DiaryRevision) error
+	// FindByDiaryID returns the revisions of a diary, oldest first.
+	FindByDiaryID(ctx context.Context, userID string, diaryID string) ([]model.DiaryRevision, error)
+	FindByID(ctx context.Context, userID string, diaryID string, id string) (model.DiaryRevision, error)
+	CountByDiaryID(ctx context.Context, userID string, diaryID string) (int64, error)
+	DeleteByDiaryID(ctx context.Context, userID string, diaryID string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+	Reassign(ctx context.Context, fromUserID string, toUserID string) error
+}
+
+// ReportRepository stores the yearly reports of users.
+type ReportRepository interface {
+	// FindYearly returns the report of the year, which may not have been
+	// generated yet.
+	FindYearly(ctx context.Context, userID string, year int) (model.YearlyReport, error)
+	// MarkChanged counts a change to the diaries of the year.
+	MarkChanged(ctx context.Context, userID string, year int) error
+	// MarkAllChanged counts a change to every stored report of the user.
+	MarkAllChanged(ctx context.Context, userID string) error
+	// SaveYearly stores the stats of a report generated at its
+	// GeneratedChanges, and leaves the count of changes alone.
+	SaveYearly(ctx context.Context, report model.YearlyReport) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+type SessionRepository interface {
+	Insert(ctx context.Context, session model.Session) error
+	FindByID(ctx context.Context, id string) (model.Session, error)
+	// FindByUserID returns the sessions of the user, most recently seen
+	// first.
+	FindByUserID(ctx context.Context, userID string) ([]model.Session, error)
+	// Rotate replaces the token hash of the session if it is still oldHash,
+	// and fails with ErrNotFound otherwise.
+	Rotate(ctx context.Context, id string, oldHash string, newHash string, seenAt time.Time, expiresAt time.Time) error
+	// Touch records that the session was used at seenAt.
+	Touch(ctx context.Context, id string, seenAt time.Time) error
+	Delete(ctx context.Context, userID string, id string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+type FavoriteRepository interface {
+	Insert(ctx context.Context, favorite model.Favorite) error
+	FindByUserID(ctx context.Context, userID string) ([]model.Favorite, error)
+	Delete(ctx context.Context, userID string, quote string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+	Exists(ctx context.Context, userID string, quote string) (bool, error)
+	Reassign(ctx context.Context, fromUserID string, toUserID string) error
+}
+
+// EmotionRepository stores the emotion catalog. Retired emotions are found
+// and exist like the others.
+type EmotionRepository interface {
+	// FindAll returns the emotions in display order.
+	FindAll(ctx context.Context) ([]model.Emotion, error)
+	FindByName(ctx context.Context, name string) (model.Emotion, error)
+	Exists(ctx context.Context, name string) (bool, error)
+	// Insert fails with ErrDuplicate when the name is taken.
+	Insert(ctx context.Context, emotion model.Emotion) error
+	// Update sets the emoji, color and valence of the emotion with the same
+	// name.
+	Update(ctx context.Context, emotion model.Emotion) error
+	Retire(ctx context.Context, name string, at time.Time) error
+	// Reorder sets the order of each named emotion to its index in names.
+	Reorder(ctx context.Context, names []string) error
+}
+
+type CustomEmotionRepository interface {
+	Insert(ctx context.Context, emotion model.CustomEmotion) error
+	// FindByUserID returns the custom emotions of the user, oldest first.
+	FindByUserID(ctx context.Context, userID string) ([]model.CustomEmotion, error)
+	Exists(ctx context.Context, userID string, name string) (bool, error)
+	Delete(ctx context.Context, userID string, name string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+	Reassign(ctx context.Context, fromUserID string, toUserID string) error
+}
+
+type ThemeRepository interface {
+	FindAll(ctx context.Context) ([]model.Theme, error)
+	Exists(ctx context.Context, name string) (bool, error)
+}
+
+// StatsRepository aggregates the diaries that are not in the trash. Periods
+// begin at midnight in loc, and weeks begin on weekStart.
+type StatsRepository interface {
+	// CountDiaries counts the diaries in the range and the distinct days
+	// they were written on.
+	CountDiaries(ctx context.Context, userID string, from, to time.Time, loc *time.Location) (DiaryCount, error)
+	// CountByDay counts the diaries of each day in the range that has any,
+	// oldest day first.
+	CountByDay(ctx context.Context, userID string, from, to time.Time, loc *time.Location) ([]DayCount, error)
+	// CountEmotions counts the diaries with each emotion in the range.
+	CountEmotions(ctx context.Context, userID string, from, to time.Time) (map[string]int, error)
+	// CountEmotionsByPeriod counts the diaries with each emotion in the range,
+	// grouped by the period of unit they fall in.
+	CountEmotionsByPeriod(ctx context.Context, userID string, from, to time.Time, unit period.Unit, weekStart time.Weekday, loc *time.Location) ([]EmotionCount, error)
+	// CountThemes counts the diaries with each theme in the range.
+	CountThemes(ctx context.Context, userID string, from, to time.Time) (map[string]int, error)
+	// CountEmotionPairs counts the diaries in the range that have both
+	// emotions of a pair, for every pair that occurs.
+	CountEmotionPairs(ctx context.Context, userID string, from, to time.Time) ([]EmotionPairCount, error)
+	// CountEmotionsByWeekday counts the diaries with each emotion in the
+	// range, grouped by the day of the week in loc they were written on.
+	CountEmotionsByWeekday(ctx context.Context, userID string, from, to time.Time, loc *time.Location) ([]WeekdayEmotionCount, error)
+	// CountEmotionsByTheme counts the diaries with each emotion in the range,
+	// grouped by their theme.
+	CountEmotionsByTheme(ctx context.Context, userID string, from, to time.Time) ([]ThemeEmotionCount, error)
+}
+
+type DiaryCount struct {
+	Entries int64
+	Days    int64
+}
+
+// DayCount is the number of diaries on the day that begins at Date.
+type DayCount struct {
+	Date  time.Time
+	Count int
+}
+
+// EmotionCount is the number of diaries with an emotion in the period that
+// begins at Start.
+type EmotionCount struct {
+	Start   time.Time
+	Emotion string
+	Count   int
+}
+
+// EmotionPairCount is the number of diaries with both emotions. A sorts
+// before B.
+type EmotionPairCount struct {
+	A     string
+	B     string
+	Count int
+}
+
+type WeekdayEmotionCount struct {
+	Weekday time.Weekday
+	Emotion string
+	Count   int
+}
+
+type ThemeEmotionCount struct {
+	Theme   string
+	Emotion string
+	Count   int
+}
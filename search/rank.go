@@ -0,0 +1,84 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+	// phraseBoost multiplies the score of documents that contain the whole
+	// query as written.
+	phraseBoost = 1.5
+)
+
+// Document is what Rank needs to know about a document: how often each query
+// term occurs in it and its length, as returned by Counts, and whether it
+// contains the query as written.
+type Document struct {
+	ID     string
+	Counts map[string]int
+	Length int
+	Phrase bool
+}
+
+// NewDocument describes text for ranking against query.
+func NewDocument(id string, text string, query string) Document {
+	counts, length := Counts(text)
+	return Document{
+		ID:     id,
+		Counts: counts,
+		Length: length,
+		Phrase: ContainsPhrase(text, query),
+	}
+}
+
+// ContainsPhrase reports whether text contains query as written, ignoring
+// case and differences in white space.
+func ContainsPhrase(text string, query string) bool {
+	phrase := normalize(query)
+	return phrase != "" && strings.Contains(normalize(text), phrase)
+}
+
+type Result struct {
+	ID    string
+	Score float64
+}
+
+// Rank scores documents against query with BM25 and returns them best first.
+// Every document is expected to contain all query terms, which makes the
+// inverse document frequency the same for each term, so it is left out.
+func Rank(query string, docs []Document) []Result {
+	terms := QueryTerms(query)
+	var total int
+	for _, doc := range docs {
+		total += doc.Length
+	}
+	avg := 1.0
+	if len(docs) > 0 && total > 0 {
+		avg = float64(total) / float64(len(docs))
+	}
+	results := make([]Result, len(docs))
+	for i, doc := range docs {
+		var score float64
+		norm := bm25K1 * (1 - bm25B + bm25B*float64(doc.Length)/avg)
+		for _, term := range terms {
+			tf := float64(doc.Counts[term])
+			score += tf * (bm25K1 + 1) / (tf + norm)
+		}
+		if doc.Phrase {
+			score *= phraseBoost
+		}
+		results[i] = Result{
+			ID:    doc.ID,
+			Score: math.Round(score*1000) / 1000,
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
@@ -0,0 +1,100 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		texts map[string]string
+		order []string
+	}{
+		{
+			name:  "more occurrences first",
+			query: "행복",
+			texts: map[string]string{"once": "행복", "twice": "행복 행복"},
+			order: []string{"twice", "once"},
+		},
+		{
+			name:  "shorter documents first",
+			query: "행복",
+			texts: map[string]string{"long": "행복 그리고 아주 길고 긴 다른 이야기", "short": "행복 이야기"},
+			order: []string{"short", "long"},
+		},
+		{
+			name:  "phrase first",
+			query: "good day",
+			texts: map[string]string{"apart": "day good", "phrase": "good day"},
+			order: []string{"phrase", "apart"},
+		},
+		{
+			name:  "phrase ignores case and spacing",
+			query: "Good Day",
+			texts: map[string]string{"apart": "day good", "phrase": "good\n  day"},
+			order: []string{"phrase", "apart"},
+		},
+		{
+			name:  "prefixes count",
+			query: "happ",
+			texts: map[string]string{"one": "happy day", "two": "happy happier"},
+			order: []string{"two", "one"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var docs []Document
+			// Ties keep the order of the documents, so the expected
+			// winner goes last.
+			for i := len(tt.order) - 1; i >= 0; i-- {
+				id := tt.order[i]
+				docs = append(docs, NewDocument(id, tt.texts[id], tt.query))
+			}
+			var order []string
+			for _, result := range Rank(tt.query, docs) {
+				order = append(order, result.ID)
+			}
+			if !reflect.DeepEqual(order, tt.order) {
+				t.Errorf("got order %q, want %q", order, tt.order)
+			}
+		})
+	}
+}
+
+func TestRankKeepsOrderOfTies(t *testing.T) {
+	docs := []Document{
+		NewDocument("a", "행복", "행복"),
+		NewDocument("b", "행복", "행복"),
+		NewDocument("c", "행복", "행복"),
+	}
+	var order []string
+	for _, result := range Rank("행복", docs) {
+		order = append(order, result.ID)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("got order %q, want %q", order, want)
+	}
+}
+
+// TestRankFromCounts checks that documents described by stored counts rank
+// like documents made from their text.
+func TestRankFromCounts(t *testing.T) {
+	texts := map[string]string{"a": "오늘은 행복한 하루", "b": "행복 행복 행복", "c": "행복한 하루 끝에 또 행복"}
+	query := "행복한"
+	var fromText, fromCounts []Document
+	for _, id := range []string{"a", "b", "c"} {
+		fromText = append(fromText, NewDocument(id, texts[id], query))
+		counts, length := Counts(texts[id])
+		fromCounts = append(fromCounts, Document{
+			ID:     id,
+			Counts: counts,
+			Length: length,
+			Phrase: ContainsPhrase(texts[id], query),
+		})
+	}
+	if got, want := Rank(query, fromCounts), Rank(query, fromText); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"sort"
+)
+
+const (
+	snippetLength = 80
+	snippetBefore = 20
+	ellipsis      = '…'
+)
+
+// Snippet is an excerpt of a document around the first match. Highlights
+// are half-open [start, end) offsets in runes into Text.
+type Snippet struct {
+	Text       string
+	Highlights [][2]int
+}
+
+// MakeSnippet cuts an excerpt of text around the first place a query term
+// occurs and marks every occurrence of a query term inside it.
+func MakeSnippet(text string, query string) Snippet {
+	src := []rune(text)
+	ranges := matches(text, QueryTerms(query))
+	start := 0
+	if len(ranges) > 0 && ranges[0][0] > snippetBefore {
+		start = ranges[0][0] - snippetBefore
+	}
+	end := start + snippetLength
+	if end > len(src) {
+		end = len(src)
+	}
+	var out []rune
+	offset := -start
+	if start > 0 {
+		out = append(out, ellipsis)
+		offset++
+	}
+	out = append(out, src[start:end]...)
+	if end < len(src) {
+		out = append(out, ellipsis)
+	}
+	snippet := Snippet{
+		Text:       string(out),
+		Highlights: [][2]int{},
+	}
+	for _, r := range ranges {
+		if r[1] <= start || r[0] >= end {
+			continue
+		}
+		if r[0] < start {
+			r[0] = start
+		}
+		if r[1] > end {
+			r[1] = end
+		}
+		snippet.Highlights = append(snippet.Highlights, [2]int{r[0] + offset, r[1] + offset})
+	}
+	return snippet
+}
+
+// matches finds the rune ranges of text where a term occurs, merged and in
+// order.
+func matches(text string, terms []string) [][2]int {
+	var ranges [][2]int
+	rs := runs(text)
+	for _, term := range terms {
+		ranges = append(ranges, occurrences(rs, term)...)
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i][0] < ranges[j][0]
+	})
+	var merged [][2]int
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r[0] <= merged[n-1][1] {
+			if r[1] > merged[n-1][1] {
+				merged[n-1][1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// occurrences finds the rune ranges where term occurs in runs. Words match
+// the start of a run, CJK terms anywhere inside one.
+func occurrences(runs []run, term string) [][2]int {
+	var ranges [][2]int
+	t := []rune(term)
+	for _, run := range runs {
+		if !run.cjk {
+			if len(t) <= len(run.text) && string(run.text[:len(t)]) == term {
+				ranges = append(ranges, [2]int{run.start, run.start + len(t)})
+			}
+			continue
+		}
+		for i := 0; i+len(t) <= len(run.text); i++ {
+			if string(run.text[i:i+len(t)]) == term {
+				ranges = append(ranges, [2]int{run.start + i, run.start + i + len(t)})
+			}
+		}
+	}
+	return ranges
+}
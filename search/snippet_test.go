@@ -0,0 +1,98 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMakeSnippet(t *testing.T) {
+	before := strings.Repeat("가", 30)
+	after := strings.Repeat("나", 100)
+	tests := []struct {
+		name  string
+		text  string
+		query string
+		want  Snippet
+	}{
+		{
+			name:  "empty",
+			text:  "",
+			query: "행복",
+			want:  Snippet{Text: "", Highlights: [][2]int{}},
+		},
+		{
+			name:  "no match starts at the beginning",
+			text:  "nothing here",
+			query: "행복",
+			want:  Snippet{Text: "nothing here", Highlights: [][2]int{}},
+		},
+		{
+			name:  "short text",
+			text:  "오늘은 행복",
+			query: "행복",
+			want:  Snippet{Text: "오늘은 행복", Highlights: [][2]int{{4, 6}}},
+		},
+		{
+			name:  "match at the start",
+			text:  "행복" + after,
+			query: "행복",
+			want:  Snippet{Text: "행복" + after[:len("나")*78] + "…", Highlights: [][2]int{{0, 2}}},
+		},
+		{
+			name:  "match within the lead",
+			text:  strings.Repeat("가", snippetBefore) + "행복",
+			query: "행복",
+			want:  Snippet{Text: strings.Repeat("가", snippetBefore) + "행복", Highlights: [][2]int{{20, 22}}},
+		},
+		{
+			name:  "match past the lead",
+			text:  before + "행복" + after,
+			query: "행복",
+			want: Snippet{
+				Text:       "…" + strings.Repeat("가", snippetBefore) + "행복" + strings.Repeat("나", snippetLength-snippetBefore-2) + "…",
+				Highlights: [][2]int{{21, 23}},
+			},
+		},
+		{
+			name:  "long text without a match",
+			text:  strings.Repeat("x", 90),
+			query: "zz",
+			want:  Snippet{Text: strings.Repeat("x", snippetLength) + "…", Highlights: [][2]int{}},
+		},
+		{
+			name:  "adjacent matches merge",
+			text:  "행복행복",
+			query: "행복",
+			want:  Snippet{Text: "행복행복", Highlights: [][2]int{{0, 4}}},
+		},
+		{
+			name:  "word prefixes",
+			text:  "Happy day, happier night",
+			query: "happ",
+			want:  Snippet{Text: "Happy day, happier night", Highlights: [][2]int{{0, 4}, {11, 15}}},
+		},
+		{
+			name:  "match near the end",
+			text:  strings.Repeat("가", snippetLength-1) + "행복",
+			query: "행복",
+			want:  Snippet{Text: "…" + strings.Repeat("가", snippetBefore) + "행복", Highlights: [][2]int{{21, 23}}},
+		},
+		{
+			name:  "highlight cut at the end",
+			text:  "행복" + strings.Repeat("가", snippetLength-3) + "행복나나",
+			query: "행복",
+			want: Snippet{
+				Text:       "행복" + strings.Repeat("가", snippetLength-3) + "행…",
+				Highlights: [][2]int{{0, 2}, {snippetLength - 1, snippetLength}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MakeSnippet(tt.text, tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MakeSnippet(%q, %q) = %q, want %q", tt.text, tt.query, got, tt.want)
+			}
+		})
+	}
+}
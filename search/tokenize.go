@@ -0,0 +1,157 @@
+// Package search implements the full-text search of diaries. Text is split
+// into terms that work for Korean without a dictionary: runs of Hangul or
+// other CJK characters become overlapping bigrams, and everything else is
+// split into lowercase words that also match by their prefixes.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Version changes whenever Terms or Counts index text differently, so that
+// stored terms can be rebuilt.
+const Version = 3
+
+// maxPrefix is the length in runes of the longest word prefix that is
+// indexed. A query word longer than that only matches the whole word.
+const maxPrefix = 16
+
+// Terms returns the distinct terms a document is indexed under. CJK runs
+// are indexed by their single characters as well as their bigrams, so that
+// one-character queries still match. Words are indexed by their prefixes of
+// up to maxPrefix runes as well as by themselves, so that "happ" matches
+// "happy".
+func Terms(text string) []string {
+	seen := make(map[string]struct{})
+	var terms []string
+	add := func(term string) {
+		if _, ok := seen[term]; !ok {
+			seen[term] = struct{}{}
+			terms = append(terms, term)
+		}
+	}
+	for _, run := range runs(text) {
+		if !run.cjk {
+			for i := 1; i <= len(run.text) && i <= maxPrefix; i++ {
+				add(string(run.text[:i]))
+			}
+			add(string(run.text))
+			continue
+		}
+		for i := range run.text {
+			add(string(run.text[i]))
+			if i+1 < len(run.text) {
+				add(string(run.text[i : i+2]))
+			}
+		}
+	}
+	return terms
+}
+
+// Counts returns how often each term of text occurs, counted the way Rank
+// counts the occurrences of a query term, and the length of text: a word
+// counts as one and a CJK run as its number of bigrams. Together they let
+// documents be ranked without their text.
+func Counts(text string) (map[string]int, int) {
+	counts := make(map[string]int)
+	length := 0
+	for _, run := range runs(text) {
+		if !run.cjk {
+			for i := 1; i <= len(run.text) && i <= maxPrefix; i++ {
+				counts[string(run.text[:i])]++
+			}
+			if len(run.text) > maxPrefix {
+				counts[string(run.text)]++
+			}
+			length++
+			continue
+		}
+		for i := range run.text {
+			counts[string(run.text[i])]++
+			if i+1 < len(run.text) {
+				counts[string(run.text[i:i+2])]++
+			}
+		}
+		if len(run.text) > 1 {
+			length += len(run.text) - 1
+		} else {
+			length++
+		}
+	}
+	return counts, length
+}
+
+// QueryTerms returns the distinct terms a document has to contain to match
+// query. A CJK run is matched by its bigrams, or by itself when it is a single
+// character.
+func QueryTerms(query string) []string {
+	seen := make(map[string]struct{})
+	var terms []string
+	add := func(term string) {
+		if _, ok := seen[term]; !ok {
+			seen[term] = struct{}{}
+			terms = append(terms, term)
+		}
+	}
+	for _, run := range runs(query) {
+		if !run.cjk || len(run.text) == 1 {
+			add(string(run.text))
+			continue
+		}
+		for i := 0; i+1 < len(run.text); i++ {
+			add(string(run.text[i : i+2]))
+		}
+	}
+	return terms
+}
+
+type run struct {
+	text []rune
+	cjk  bool
+	// start is the offset of the run in the runes of the original text.
+	start int
+}
+
+// runs splits text into lowercase runs of CJK characters and of other
+// letters and digits. Everything else separates runs.
+func runs(text string) []run {
+	var runs []run
+	var cur *run
+	for i, r := range []rune(text) {
+		kind := classify(r)
+		if kind == other {
+			cur = nil
+			continue
+		}
+		cjk := kind == cjkChar
+		if cur == nil || cur.cjk != cjk {
+			runs = append(runs, run{cjk: cjk, start: i})
+			cur = &runs[len(runs)-1]
+		}
+		cur.text = append(cur.text, unicode.ToLower(r))
+	}
+	return runs
+}
+
+type charKind int
+
+const (
+	other charKind = iota
+	wordChar
+	cjkChar
+)
+
+func classify(r rune) charKind {
+	switch {
+	case unicode.In(r, unicode.Hangul, unicode.Han, unicode.Hiragana, unicode.Katakana):
+		return cjkChar
+	case unicode.IsLetter(r) || unicode.IsDigit(r):
+		return wordChar
+	}
+	return other
+}
+
+func normalize(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
@@ -0,0 +1,113 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	long := "abcdefghijklmnopqrs"
+	var longTerms []string
+	for i := 1; i <= maxPrefix; i++ {
+		longTerms = append(longTerms, long[:i])
+	}
+	longTerms = append(longTerms, long)
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"punctuation only", "!?…", nil},
+		{"hangul bigrams", "오늘", []string{"오", "오늘", "늘"}},
+		{"hangul single character", "봄", []string{"봄"}},
+		{"hangul runs split by spaces", "오늘 행복", []string{"오", "오늘", "늘", "행", "행복", "복"}},
+		{"repeated terms once", "행복 행복", []string{"행", "행복", "복"}},
+		{"han and kana", "日本", []string{"日", "日本", "本"}},
+		{"word prefixes", "Day", []string{"d", "da", "day"}},
+		{"digits", "2024년", []string{"2", "20", "202", "2024", "년"}},
+		{"hangul next to latin", "행복happy", []string{"행", "행복", "복", "h", "ha", "hap", "happ", "happy"}},
+		{"long word", long, longTerms},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Terms(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Terms(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryTerms(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"empty", "", nil},
+		{"punctuation only", "...", nil},
+		{"hangul bigrams", "행복한 하루", []string{"행복", "복한", "하루"}},
+		{"hangul single character", "봄", []string{"봄"}},
+		{"repeated bigrams once", "하하하", []string{"하하"}},
+		{"words are lowercased", "Happy DAY", []string{"happy", "day"}},
+		{"mixed", "좋은 morning", []string{"좋은", "morning"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QueryTerms(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryTerms(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestQueryTermsAreIndexed checks that a document is indexed under every
+// query term of a part of it, which is what makes it a search result.
+func TestQueryTermsAreIndexed(t *testing.T) {
+	text := "오늘은 정말 Happy한 하루였다"
+	for _, query := range []string{"오늘", "정말 하루", "happ", "Happy", "하루였", "루"} {
+		terms := Terms(text)
+		for _, term := range QueryTerms(query) {
+			found := false
+			for _, indexed := range terms {
+				found = found || indexed == term
+			}
+			if !found {
+				t.Errorf("query %q: term %q is not indexed", query, term)
+			}
+		}
+	}
+}
+
+func TestCounts(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		counts map[string]int
+		length int
+	}{
+		{"empty", "", map[string]int{}, 0},
+		{"hangul", "행복 행복해", map[string]int{"행": 2, "복": 2, "행복": 2, "해": 1, "복해": 1}, 3},
+		{"single character", "봄", map[string]int{"봄": 1}, 1},
+		{"word prefixes", "happy happier", map[string]int{
+			"h": 2, "ha": 2, "hap": 2, "happ": 2, "happy": 1,
+			"happi": 1, "happie": 1, "happier": 1,
+		}, 2},
+		{"long word", strings.Repeat("a", maxPrefix+1), func() map[string]int {
+			counts := make(map[string]int)
+			for i := 1; i <= maxPrefix+1; i++ {
+				counts[strings.Repeat("a", i)] = 1
+			}
+			return counts
+		}(), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts, length := Counts(tt.text)
+			if !reflect.DeepEqual(counts, tt.counts) || length != tt.length {
+				t.Errorf("Counts(%q) = %v, %d; want %v, %d", tt.text, counts, length, tt.counts, tt.length)
+			}
+		})
+	}
+}
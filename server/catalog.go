@@ -0,0 +1,304 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+	"dailyscoop-backend/service"
+)
+
+// colorPattern matches colors written as #RRGGBB.
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+type emotionResponse struct {
+	Name    string `json:"name"`
+	Emoji   string `json:"emoji"`
+	Color   string `json:"color"`
+	Valence string `json:"valence"`
+	Order   int    `json:"order"`
+	Retired bool   `json:"retired"`
+}
+
+func newEmotionResponse(emotion model.Emotion) emotionResponse {
+	return emotionResponse{
+		Name:    emotion.Name,
+		Emoji:   emotion.Emoji,
+		Color:   emotion.Color,
+		Valence: emotion.Valence,
+		Order:   emotion.Order,
+		Retired: emotion.Retired(),
+	}
+}
+
+// GetEmotions lists the emotion catalog in display order. Retired emotions
+// are listed too, so that older diaries that have them can be shown.
+func (s *Server) GetEmotions(c echo.Context) error {
+	emotions, err := s.ds.Emotions(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	resp := struct {
+		Emotions []emotionResponse `json:"emotions"`
+	}{
+		Emotions: []emotionResponse{},
+	}
+	for _, emotion := range emotions {
+		resp.Emotions = append(resp.Emotions, newEmotionResponse(emotion))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) AddEmotion(c echo.Context) error {
+	var req struct {
+		Name    string
+		Emoji   string
+		Color   string
+		Valence string
+	}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if req.Name == "" || req.Emoji == "" || !colorPattern.MatchString(req.Color) {
+		return echo.NewHTTPError(http.StatusBadRequest, "파라미터가 올바르지 않습니다.")
+	}
+	emotion, err := s.ds.AddEmotion(c.Request().Context(), model.Emotion{
+		Name:    req.Name,
+		Emoji:   req.Emoji,
+		Color:   req.Color,
+		Valence: req.Valence,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidValence) {
+			return echo.NewHTTPError(http.StatusBadRequest, "valence는 positive 또는 negative여야 합니다.")
+		}
+		if errors.Is(err, service.ErrEmotionExists) {
+			return echo.NewHTTPError(http.StatusConflict, "이미 존재하는 감정입니다.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusCreated, newEmotionResponse(emotion))
+}
+
+// PatchEmotion changes the emoji, color or valence of an emotion of the
+// catalog.
+func (s *Server) PatchEmotion(c echo.Context) error {
+	var req struct {
+		Emoji   *string
+		Color   *string
+		Valence *string
+	}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if req.Emoji == nil && req.Color == nil && req.Valence == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "변경할 내용이 없습니다.")
+	}
+	if (req.Emoji != nil && *req.Emoji == "") || (req.Color != nil && !colorPattern.MatchString(*req.Color)) {
+		return echo.NewHTTPError(http.StatusBadRequest, "파라미터가 올바르지 않습니다.")
+	}
+	emotion, err := s.ds.PatchEmotion(c.Request().Context(), c.Param("name"), service.EmotionPatch{
+		Emoji:   req.Emoji,
+		Color:   req.Color,
+		Valence: req.Valence,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidValence) {
+			return echo.NewHTTPError(http.StatusBadRequest, "valence는 positive 또는 negative여야 합니다.")
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "존재하지 않는 감정입니다.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusOK, newEmotionResponse(emotion))
+}
+
+func (s *Server) RetireEmotion(c echo.Context) error {
+	if err := s.ds.RetireEmotion(c.Request().Context(), c.Param("name")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "존재하지 않는 감정입니다.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "감정을 더 이상 선택할 수 없게 했습니다.",
+	})
+}
+
+func (s *Server) ReorderEmotions(c echo.Context) error {
+	var req struct {
+		Names []string
+	}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := s.ds.ReorderEmotions(c.Request().Context(), req.Names); err != nil {
+		if errors.Is(err, service.ErrIncompleteOrder) {
+			return echo.NewHTTPError(http.StatusBadRequest, "모든 감정을 한 번씩 나열해주세요.")
+		}
+		return err
+	}
+	return s.GetEmotions(c)
+}
+
+type customEmotionResponse struct {
+	Name    string `json:"name"`
+	Emoji   string `json:"emoji"`
+	Valence string `json:"valence"`
+}
+
+func (s *Server) GetCustomEmotions(c echo.Context) error {
+	emotions, err := s.ds.CustomEmotions(c.Request().Context(), s.GetUserID(c))
+	if err != nil {
+		return err
+	}
+	resp := struct {
+		Emotions []customEmotionResponse `json:"emotions"`
+	}{
+		Emotions: []customEmotionResponse{},
+	}
+	for _, emotion := range emotions {
+		resp.Emotions = append(resp.Emotions, customEmotionResponse{
+			Name:    emotion.Name,
+			Emoji:   emotion.Emoji,
+			Valence: emotion.Valence,
+		})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) AddCustomEmotion(c echo.Context) error {
+	var req struct {
+		Name    string
+		Emoji   string
+		Valence string
+	}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if req.Name == "" || req.Emoji == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "파라미터가 올바르지 않습니다.")
+	}
+	emotion, err := s.ds.AddCustomEmotion(c.Request().Context(), model.CustomEmotion{
+		UserID:  s.GetUserID(c),
+		Name:    req.Name,
+		Emoji:   req.Emoji,
+		Valence: req.Valence,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidValence) {
+			return echo.NewHTTPError(http.StatusBadRequest, "valence는 positive 또는 negative여야 합니다.")
+		}
+		if errors.Is(err, service.ErrEmotionExists) {
+			return echo.NewHTTPError(http.StatusConflict, "이미 존재하는 감정입니다.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusCreated, customEmotionResponse{
+		Name:    emotion.Name,
+		Emoji:   emotion.Emoji,
+		Valence: emotion.Valence,
+	})
+}
+
+func (s *Server) DeleteCustomEmotion(c echo.Context) error {
+	if err := s.ds.DeleteCustomEmotion(c.Request().Context(), s.GetUserID(c), c.Param("name")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "존재하지 않는 감정입니다.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "감정을 삭제했습니다.",
+	})
+}
+
+type themeResponse struct {
+	Name           string            `json:"name"`
+	Titles         map[string]string `json:"titles"`
+	Assets         map[string]string `json:"assets"`
+	Palette        []string          `json:"palette"`
+	AvailableFrom  *time.Time        `json:"available_from,omitempty"`
+	AvailableUntil *time.Time        `json:"available_until,omitempty"`
+	Available      bool              `json:"available"`
+}
+
+// GetThemes lists the theme catalog. The response carries an ETag of its
+// body, so clients can revalidate it with If-None-Match and only download
+// the assets again when a theme changed.
+func (s *Server) GetThemes(c echo.Context) error {
+	themes, err := s.ds.Themes(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	resp := struct {
+		Themes []themeResponse `json:"themes"`
+	}{
+		Themes: []themeResponse{},
+	}
+	for _, theme := range themes {
+		resp.Themes = append(resp.Themes, themeResponse{
+			Name:           theme.Name,
+			Titles:         nonNilStrings(theme.Titles),
+			Assets:         nonNilStrings(theme.Assets),
+			Palette:        append([]string{}, theme.Palette...),
+			AvailableFrom:  theme.AvailableFrom,
+			AvailableUntil: theme.AvailableUntil,
+			Available:      theme.Available(now),
+		})
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Response().Header().Set("ETag", etag)
+	c.Response().Header().Set("Cache-Control", "no-cache")
+	if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.JSONBlob(http.StatusOK, body)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func nonNilStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+// requireAdmin lets only the users listed in the server admins through.
+func (s *Server) requireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := s.GetUserID(c)
+		for _, admin := range s.cfg.Server.Admins {
+			if admin == id {
+				return next(c)
+			}
+		}
+		return echo.NewHTTPError(http.StatusForbidden, "권한이 없습니다.")
+	}
+}
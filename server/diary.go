@@ -5,76 +5,264 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
-	"go.mongodb.org/mongo-driver/mongo"
 
 	"dailyscoop-backend/model"
+	"dailyscoop-backend/period"
+	"dailyscoop-backend/repository"
+	"dailyscoop-backend/service"
 )
 
+type diaryResponse struct {
+	ID       string    `json:"id"`
+	Content  string    `json:"content"`
+	Image    string    `json:"image"`
+	Date     time.Time `json:"date"`
+	Emotions []string  `json:"emotions"`
+	Theme    string    `json:"theme"`
+}
+
+// newDiaryResponse renders a diary with its date in loc, the time zone of the
+// user.
+func newDiaryResponse(diary model.Diary, loc *time.Location) diaryResponse {
+	return diaryResponse{
+		ID:       diary.ID,
+		Content:  diary.Content,
+		Image:    diary.Image,
+		Date:     diary.Date.In(loc),
+		Emotions: diary.Emotions,
+		Theme:    diary.Theme,
+	}
+}
+
+// diaryFields maps the names accepted by the fields query parameter to diary
+// keys.
+var diaryFields = map[string]string{
+	"id":       model.DiaryIDKey,
+	"content":  model.DiaryContentKey,
+	"image":    model.DiaryImageKey,
+	"date":     model.DiaryDateKey,
+	"emotions": model.DiaryEmotionsKey,
+	"theme":    model.DiaryThemeKey,
+}
+
+var allDiaryFields = []string{"id", "content", "image", "date", "emotions", "theme"}
+
+const maxDiaryPageSize = 100
+
+// projectDiaryResponse renders only the requested fields of a diary.
+func projectDiaryResponse(diary model.Diary, loc *time.Location, fields []string) echo.Map {
+	resp := newDiaryResponse(diary, loc)
+	m := echo.Map{}
+	for _, field := range fields {
+		switch field {
+		case "id":
+			m[field] = resp.ID
+		case "content":
+			m[field] = resp.Content
+		case "image":
+			m[field] = resp.Image
+		case "date":
+			m[field] = resp.Date
+		case "emotions":
+			m[field] = resp.Emotions
+		case "theme":
+			m[field] = resp.Theme
+		}
+	}
+	return m
+}
+
 func (s *Server) GetAllDiaries(c echo.Context) error {
-	var diaries []model.Diary
 	sortStr := c.QueryParam("sort")
 	if sortStr != "" && (sortStr != "1" && sortStr != "-1") {
 		return echo.NewHTTPError(http.StatusBadRequest, "정렬기준을 확인해주세요.")
 	}
+	search := c.QueryParam("search")
 	var sort int
 	var err error
 	if sortStr == "" {
-		sort = -1
+		// Search results are ranked by relevance unless a sort is given.
+		if search == "" {
+			sort = -1
+		}
 	} else {
 		sort, err = strconv.Atoi(sortStr)
 		if err != nil {
-			return nil
+			return echo.NewHTTPError(http.StatusBadRequest, "정렬기준을 확인해주세요.")
 		}
 	}
-	content := c.QueryParam("search")
-	if content == "" {
-		diaries, err = s.ds.DiariesByUserID(c.Request().Context(), s.GetUserID(c), sort)
-		if err != nil {
-			return err
+	filter, err := s.parseDiaryFilter(c)
+	if err != nil {
+		return err
+	}
+	opts := service.DiaryListOptions{
+		Search: search,
+		Filter: filter,
+		Sort:   sort,
+		Cursor: c.QueryParam("cursor"),
+	}
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		opts.Limit, err = strconv.Atoi(limitStr)
+		if err != nil || opts.Limit < 1 || opts.Limit > maxDiaryPageSize {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit은 1에서 %d 사이여야 합니다.", maxDiaryPageSize))
 		}
-	} else {
-		diaries, err = s.ds.FindDiaries(c.Request().Context(), s.GetUserID(c), content, sort)
-		if err != nil {
-			return err
+	}
+	var fields []string
+	if fieldsStr := c.QueryParam("fields"); fieldsStr != "" {
+		for _, field := range strings.Split(fieldsStr, ",") {
+			key, ok := diaryFields[strings.TrimSpace(field)]
+			if !ok {
+				return echo.NewHTTPError(http.StatusBadRequest, "존재하지 않는 필드입니다: "+field)
+			}
+			fields = append(fields, strings.TrimSpace(field))
+			opts.Fields = append(opts.Fields, key)
+		}
+	}
+	page, err := s.ds.ListDiaries(c.Request().Context(), s.GetUserID(c), opts)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return echo.NewHTTPError(http.StatusBadRequest, "커서가 올바르지 않습니다.")
 		}
+		return err
 	}
-	type Diary struct {
-		Content  string    `json:"content"`
-		Image    string    `json:"image"`
-		Date     time.Time `json:"date"`
-		Emotions []string  `json:"emotions"`
-		Theme    string    `json:"theme"`
+	loc, err := s.location(c)
+	if err != nil {
+		return err
 	}
 	resp := struct {
-		Diaries []Diary `json:"diaries"`
+		Diaries    []interface{} `json:"diaries"`
+		NextCursor string        `json:"next_cursor,omitempty"`
 	}{
-		Diaries: []Diary{},
+		Diaries:    []interface{}{},
+		NextCursor: page.NextCursor,
 	}
-	for _, diary := range diaries {
-		resp.Diaries = append(resp.Diaries, Diary{
-			Content:  diary.Content,
-			Image:    diary.Image,
-			Date:     diary.Date,
-			Emotions: diary.Emotions,
-			Theme:    diary.Theme,
-		})
+	for i, diary := range page.Diaries {
+		if fields == nil && page.Matches == nil {
+			resp.Diaries = append(resp.Diaries, newDiaryResponse(diary, loc))
+			continue
+		}
+		f := fields
+		if f == nil {
+			f = allDiaryFields
+		}
+		m := projectDiaryResponse(diary, loc, f)
+		if page.Matches != nil {
+			match := page.Matches[i]
+			m["score"] = match.Score
+			m["snippet"] = match.Snippet.Text
+			m["highlights"] = match.Snippet.Highlights
+		}
+		resp.Diaries = append(resp.Diaries, m)
 	}
 	return c.JSON(http.StatusOK, resp)
 }
 
+// parseDiaryFilter reads the diary filters of a listing from the query. The
+// to date is inclusive.
+func (s *Server) parseDiaryFilter(c echo.Context) (service.DiaryFilter, error) {
+	var filter service.DiaryFilter
+	if emotions := c.QueryParam("emotions"); emotions != "" {
+		for _, emotion := range strings.Split(emotions, ",") {
+			if emotion = strings.TrimSpace(emotion); emotion != "" {
+				filter.Emotions = append(filter.Emotions, emotion)
+			}
+		}
+	}
+	switch c.QueryParam("emotion_match") {
+	case "", "any":
+	case "all":
+		filter.AllEmotions = true
+	default:
+		return filter, echo.NewHTTPError(http.StatusBadRequest, "emotion_match는 any 또는 all이어야 합니다.")
+	}
+	filter.Theme = c.QueryParam("theme")
+	if from := c.QueryParam("from"); from != "" {
+		date, err := s.parseDate(c, from)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "날짜 형식을 확인해주세요.")
+		}
+		filter.From = date
+	}
+	if to := c.QueryParam("to"); to != "" {
+		date, err := s.parseDate(c, to)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "날짜 형식을 확인해주세요.")
+		}
+		filter.To = date.AddDate(0, 0, 1)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, echo.NewHTTPError(http.StatusBadRequest, "시작 날짜가 종료 날짜보다 늦습니다.")
+	}
+	if hasImage := c.QueryParam("has_image"); hasImage != "" {
+		b, err := strconv.ParseBool(hasImage)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "has_image는 true 또는 false여야 합니다.")
+		}
+		filter.HasImage = &b
+	}
+	if minLength := c.QueryParam("min_length"); minLength != "" {
+		n, err := strconv.Atoi(minLength)
+		if err != nil || n < 0 {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "min_length는 0 이상의 정수여야 합니다.")
+		}
+		filter.MinLength = n
+	}
+	return filter, nil
+}
+
+// parsePeriod reads the period of a calendar or statistics request. The type
+// is daily, weekly, monthly, quarterly or yearly and selects the period that
+// contains date, with weeks beginning on week_start. The type range selects
+// the days from the from date to the to date inclusive.
+func (s *Server) parsePeriod(c echo.Context) (period.Period, error) {
+	typ := c.QueryParam("type")
+	if typ == "range" {
+		if c.QueryParam("from") == "" || c.QueryParam("to") == "" {
+			return period.Period{}, echo.NewHTTPError(http.StatusBadRequest, "날짜를 입력해주세요.")
+		}
+		from, err := s.parseDate(c, c.QueryParam("from"))
+		if err != nil {
+			return period.Period{}, echo.NewHTTPError(http.StatusBadRequest, "날짜 형식이 올바르지 않습니다.")
+		}
+		to, err := s.parseDate(c, c.QueryParam("to"))
+		if err != nil {
+			return period.Period{}, echo.NewHTTPError(http.StatusBadRequest, "날짜 형식이 올바르지 않습니다.")
+		}
+		p, err := period.Range(from, to)
+		if err != nil {
+			return period.Period{}, echo.NewHTTPError(http.StatusBadRequest, "시작 날짜가 종료 날짜보다 늦습니다.")
+		}
+		return p, nil
+	}
+	weekStart, err := period.ParseWeekStart(c.QueryParam("week_start"))
+	if err != nil {
+		return period.Period{}, echo.NewHTTPError(http.StatusBadRequest, "week_start는 sunday, monday 또는 iso여야 합니다.")
+	}
+	if c.QueryParam("date") == "" {
+		return period.Period{}, echo.NewHTTPError(http.StatusBadRequest, "날짜를 입력해주세요.")
+	}
+	date, err := s.parseDate(c, c.QueryParam("date"))
+	if err != nil {
+		return period.Period{}, echo.NewHTTPError(http.StatusBadRequest, "날짜 형식이 올바르지 않습니다.")
+	}
+	p, err := period.Of(period.Unit(typ), date, weekStart)
+	if err != nil {
+		return period.Period{}, echo.NewHTTPError(http.StatusBadRequest, "타입이 잘못되었습니다.")
+	}
+	return p, nil
+}
+
 func (s *Server) GetCalendar(c echo.Context) error {
 	var req struct {
-		Date string `query:"date"`
-		Type string `query:"type"`
 		Sort string `query:"sort"`
 	}
 	if err := c.Bind(&req); err != nil {
 		return err
 	}
-	fmt.Println(req.Date + req.Type + req.Sort)
 	if req.Sort != "" && (req.Sort != "1" && req.Sort != "-1") {
 		return echo.NewHTTPError(http.StatusBadRequest, "정렬기준을 확인해주세요.")
 	}
@@ -85,45 +273,25 @@ func (s *Server) GetCalendar(c echo.Context) error {
 	} else {
 		sort, err = strconv.Atoi(req.Sort)
 		if err != nil {
-			return nil
+			return echo.NewHTTPError(http.StatusBadRequest, "정렬기준을 확인해주세요.")
 		}
 	}
-	if req.Date == "" || req.Type == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "파라미터가 올바르지 않습니다.")
-	}
-	date, err := time.Parse("2006-01-02", req.Date)
+	p, err := s.parsePeriod(c)
 	if err != nil {
 		return err
 	}
-	var diaries []model.Diary
-	if req.Type == "monthly" || req.Type == "weekly" {
-		diaries, err = s.ds.Calendar(c.Request().Context(), s.GetUserID(c), req.Type, date, sort)
-		if err != nil {
-			return err
-		}
-	} else {
-		return echo.NewHTTPError(http.StatusBadRequest, "존재하지 않는 타입입니다.")
-	}
-	type Diary struct {
-		Content  string    `json:"content"`
-		Image    string    `json:"image"`
-		Date     time.Time `json:"date"`
-		Emotions []string  `json:"emotions"`
-		Theme    string    `json:"theme"`
+	loc := p.Start.Location()
+	diaries, err := s.ds.Calendar(c.Request().Context(), s.GetUserID(c), p, sort)
+	if err != nil {
+		return err
 	}
 	resp := struct {
-		Diaries []Diary `json:"diaries"`
+		Diaries []diaryResponse `json:"diaries"`
 	}{
-		Diaries: []Diary{},
+		Diaries: []diaryResponse{},
 	}
 	for _, diary := range diaries {
-		resp.Diaries = append(resp.Diaries, Diary{
-			Content:  diary.Content,
-			Image:    diary.Image,
-			Date:     diary.Date,
-			Emotions: diary.Emotions,
-			Theme:    diary.Theme,
-		})
+		resp.Diaries = append(resp.Diaries, newDiaryResponse(diary, loc))
 	}
 	return c.JSON(http.StatusOK, resp)
 }
@@ -131,43 +299,102 @@ func (s *Server) GetCalendar(c echo.Context) error {
 func (s *Server) GetDiary(c echo.Context) error {
 	dateString := c.Param("date")
 	userID := s.GetUserID(c)
-	date, err := time.Parse("2006-01-02", dateString)
+	date, err := s.parseDate(c, dateString)
 	if dateString == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "파라미터가 올바르지 않습니다.")
 	}
 	if err != nil {
 		return err
 	}
-	diary, err := s.ds.DiaryByUserIDAndDate(c.Request().Context(), userID, date)
-	type Diary struct {
-		Content  string    `json:"content"`
-		Image    string    `json:"image"`
-		Date     time.Time `json:"date"`
-		Emotions []string  `json:"emotions"`
-		Theme    string    `json:"theme"`
+	loc := date.Location()
+	diaries, err := s.ds.DiariesByUserIDAndDate(c.Request().Context(), userID, date)
+	if err != nil {
+		return err
+	}
+	if len(diaries) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "해당 날짜에 일기가 존재하지 않습니다.")
 	}
-	resp := Diary{
-		Content:  diary.Content,
-		Image:    diary.Image,
-		Date:     diary.Date,
-		Emotions: diary.Emotions,
-		Theme:    diary.Theme,
+	resp := struct {
+		Diaries []diaryResponse `json:"diaries"`
+	}{
+		Diaries: []diaryResponse{},
 	}
+	for _, diary := range diaries {
+		resp.Diaries = append(resp.Diaries, newDiaryResponse(diary, loc))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetMemories returns the diaries of the same day in previous years, a month
+// ago and a week ago. The date defaults to today.
+func (s *Server) GetMemories(c echo.Context) error {
+	loc, err := s.location(c)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return echo.NewHTTPError(http.StatusNotFound, "해당 날짜에 일기가 존재하지 않습니다.")
+		return err
+	}
+	date := time.Now().In(loc)
+	if dateStr := c.QueryParam("date"); dateStr != "" {
+		date, err = s.parseDate(c, dateStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "날짜 형식이 올바르지 않습니다.")
 		}
+	}
+	memories, err := s.ds.Memories(c.Request().Context(), s.GetUserID(c), date)
+	if err != nil {
 		return err
 	}
+	type Memory struct {
+		YearsAgo int             `json:"years_ago,omitempty"`
+		Date     string          `json:"date"`
+		Diaries  []diaryResponse `json:"diaries"`
+	}
+	newMemory := func(memory service.Memory) Memory {
+		m := Memory{
+			Date:    memory.Date.Format("2006-01-02"),
+			Diaries: []diaryResponse{},
+		}
+		for _, diary := range memory.Diaries {
+			m.Diaries = append(m.Diaries, newDiaryResponse(diary, loc))
+		}
+		return m
+	}
+	resp := struct {
+		Date     string   `json:"date"`
+		YearsAgo []Memory `json:"years_ago"`
+		MonthAgo Memory   `json:"month_ago"`
+		WeekAgo  Memory   `json:"week_ago"`
+	}{
+		Date:     date.Format("2006-01-02"),
+		YearsAgo: []Memory{},
+		MonthAgo: newMemory(memories.MonthAgo),
+		WeekAgo:  newMemory(memories.WeekAgo),
+	}
+	for _, memory := range memories.YearsAgo {
+		m := newMemory(memory)
+		m.YearsAgo = date.Year() - memory.Date.Year()
+		resp.YearsAgo = append(resp.YearsAgo, m)
+	}
 	return c.JSON(http.StatusOK, resp)
 }
 
+func (s *Server) GetDiaryByID(c echo.Context) error {
+	diary, err := s.ds.DiaryByID(c.Request().Context(), s.GetUserID(c), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "존재하지 않는 일기입니다.")
+		}
+		return err
+	}
+	return s.diaryJSON(c, diary)
+}
+
 func (s *Server) CreateDiary(c echo.Context) error {
 	var req struct {
 		Content  string
 		Image    string
 		Emotions []string
 		Date     string
+		Time     string
 		Theme    string
 	}
 	if err := c.Bind(&req); err != nil {
@@ -176,23 +403,17 @@ func (s *Server) CreateDiary(c echo.Context) error {
 	if req.Content == "" || req.Image == "" || len(req.Emotions) == 0 || req.Date == "" || req.Theme == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "파라미터가 올바르지 않습니다.")
 	}
-	isThemeExists, err := s.ds.ThemeExists(c.Request().Context(), req.Theme)
-	if err != nil {
+	if err := s.validateTheme(c, req.Theme); err != nil {
 		return err
 	}
-	if !isThemeExists {
-		return echo.NewHTTPError(http.StatusBadRequest, "존재하지 않는 테마입니다.")
+	if err := s.validateEmotions(c, req.Emotions); err != nil {
+		return err
 	}
-	for _, emotion := range req.Emotions {
-		isEmotionExists, err := s.ds.EmotionExists(c.Request().Context(), emotion)
-		if err != nil {
-			return err
-		}
-		if !isEmotionExists {
-			return echo.NewHTTPError(http.StatusBadRequest, "존재하지 않는 감정입니다.")
-		}
+	date, err := s.parseDate(c, req.Date)
+	if err != nil {
+		return err
 	}
-	date, err := time.Parse("2006-01-02", req.Date)
+	date, err = s.diaryTime(c, date, req.Time)
 	if err != nil {
 		return err
 	}
@@ -204,17 +425,158 @@ func (s *Server) CreateDiary(c echo.Context) error {
 		Date:     date,
 		Theme:    req.Theme,
 	}
-	if err := s.ds.WriteDiary(c.Request().Context(), diary); err != nil {
-		return err
+	diary, err = s.ds.WriteDiary(c.Request().Context(), diary)
+	if err != nil {
+		return diaryUpdateError(err)
 	}
 	return c.JSON(http.StatusOK, echo.Map{
 		"message": "일기를 작성했습니다.",
+		"id":      diary.ID,
 	})
 }
 
+// UpdateDiary replaces the fields of a diary. A diary moved to another date
+// keeps its time of day unless a time is given.
+func (s *Server) UpdateDiary(c echo.Context) error {
+	var req struct {
+		Content  string
+		Image    string
+		Emotions []string
+		Date     string
+		Time     string
+		Theme    string
+	}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if req.Content == "" || req.Image == "" || len(req.Emotions) == 0 || req.Theme == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "파라미터가 올바르지 않습니다.")
+	}
+	if err := s.validateTheme(c, req.Theme); err != nil {
+		return err
+	}
+	if err := s.validateEmotions(c, req.Emotions); err != nil {
+		return err
+	}
+	patch := service.DiaryPatch{
+		Content:  &req.Content,
+		Image:    &req.Image,
+		Emotions: req.Emotions,
+		Theme:    &req.Theme,
+	}
+	if req.Date != "" {
+		date, err := s.parseDate(c, req.Date)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "날짜 형식이 올바르지 않습니다.")
+		}
+		patch.Date = &date
+	}
+	if req.Time != "" {
+		clock, err := parseClock(req.Time)
+		if err != nil {
+			return err
+		}
+		patch.Clock = &clock
+	}
+	diary, err := s.ds.PatchDiary(c.Request().Context(), s.GetUserID(c), c.Param("id"), patch)
+	if err != nil {
+		return diaryUpdateError(err)
+	}
+	return s.diaryJSON(c, diary)
+}
+
+func (s *Server) PatchDiary(c echo.Context) error {
+	patch, err := s.bindDiaryPatch(c)
+	if err != nil {
+		return err
+	}
+	diary, err := s.ds.PatchDiary(c.Request().Context(), s.GetUserID(c), c.Param("id"), patch)
+	if err != nil {
+		return diaryUpdateError(err)
+	}
+	return s.diaryJSON(c, diary)
+}
+
+func (s *Server) PatchDiaryByDate(c echo.Context) error {
+	date, err := s.parseDate(c, c.Param("date"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "날짜 형식이 올바르지 않습니다.")
+	}
+	patch, err := s.bindDiaryPatch(c)
+	if err != nil {
+		return err
+	}
+	diary, err := s.ds.PatchDiaryByDate(c.Request().Context(), s.GetUserID(c), date, patch)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "해당 날짜에 일기가 존재하지 않습니다.")
+		}
+		if errors.Is(err, service.ErrAmbiguousDate) {
+			return echo.NewHTTPError(http.StatusConflict, "해당 날짜에 일기가 여러 개 있습니다. 일기 ID로 수정해주세요.")
+		}
+		return diaryUpdateError(err)
+	}
+	return s.diaryJSON(c, diary)
+}
+
+// bindDiaryPatch reads a partial diary update. Only the fields present in the
+// request are validated. A diary moved to another date keeps its time of day
+// unless a time is given.
+func (s *Server) bindDiaryPatch(c echo.Context) (service.DiaryPatch, error) {
+	var req struct {
+		Content  *string
+		Image    *string
+		Emotions []string
+		Date     *string
+		Time     *string
+		Theme    *string
+	}
+	if err := c.Bind(&req); err != nil {
+		return service.DiaryPatch{}, err
+	}
+	if req.Content == nil && req.Image == nil && req.Emotions == nil && req.Date == nil && req.Time == nil && req.Theme == nil {
+		return service.DiaryPatch{}, echo.NewHTTPError(http.StatusBadRequest, "변경할 내용이 없습니다.")
+	}
+	if (req.Content != nil && *req.Content == "") || (req.Image != nil && *req.Image == "") ||
+		(req.Emotions != nil && len(req.Emotions) == 0) || (req.Theme != nil && *req.Theme == "") {
+		return service.DiaryPatch{}, echo.NewHTTPError(http.StatusBadRequest, "파라미터가 올바르지 않습니다.")
+	}
+	patch := service.DiaryPatch{
+		Content:  req.Content,
+		Image:    req.Image,
+		Emotions: req.Emotions,
+		Theme:    req.Theme,
+	}
+	if req.Theme != nil {
+		if err := s.validateTheme(c, *req.Theme); err != nil {
+			return service.DiaryPatch{}, err
+		}
+	}
+	if req.Emotions != nil {
+		if err := s.validateEmotions(c, req.Emotions); err != nil {
+			return service.DiaryPatch{}, err
+		}
+	}
+	if req.Date != nil {
+		date, err := s.parseDate(c, *req.Date)
+		if err != nil {
+			return service.DiaryPatch{}, echo.NewHTTPError(http.StatusBadRequest, "날짜 형식이 올바르지 않습니다.")
+		}
+		patch.Date = &date
+	}
+	if req.Time != nil {
+		clock, err := parseClock(*req.Time)
+		if err != nil {
+			return service.DiaryPatch{}, err
+		}
+		patch.Clock = &clock
+	}
+	return patch, nil
+}
+
 func (s *Server) DeleteDiary(c echo.Context) error {
 	dateString := c.Param("date")
-	date, err := time.Parse("2006-01-02", dateString)
+	date, err := s.parseDate(c, dateString)
 	if err != nil {
 		return err
 	}
@@ -222,47 +584,44 @@ func (s *Server) DeleteDiary(c echo.Context) error {
 		return err
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "일기를 삭제했습니다.",
+		"message": "일기를 휴지통으로 옮겼습니다.",
 	})
 }
 
-func (s *Server) CountDiaries(c echo.Context) error {
-	userID := s.GetUserID(c)
-	typ := c.QueryParam("type")
-	if typ == "" || (typ != "weekly" && typ != "monthly" && typ != "yearly") {
-		return echo.NewHTTPError(http.StatusBadRequest, "타입이 잘못되었습니다.")
-	}
-	if c.QueryParam("date") == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "날짜를 입력해주세요.")
+func (s *Server) DeleteDiaryByID(c echo.Context) error {
+	if err := s.ds.DeleteDiaryByID(c.Request().Context(), s.GetUserID(c), c.Param("id")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "존재하지 않는 일기입니다.")
+		}
+		return err
 	}
-	date, err := time.Parse("2006-01-02", c.QueryParam("date"))
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "일기를 휴지통으로 옮겼습니다.",
+	})
+}
+
+func (s *Server) CountDiaries(c echo.Context) error {
+	p, err := s.parsePeriod(c)
 	if err != nil {
 		return err
 	}
-	diaryCount, dayCount, err := s.ds.CountDiaries(c.Request().Context(), typ, date, userID)
+	count, err := s.ds.CountDiaries(c.Request().Context(), s.GetUserID(c), p)
 	if err != nil {
 		return err
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"diary_count": diaryCount,
-		"day_count":   dayCount,
+		"diary_count":  count.Diaries,
+		"days_written": count.DaysWritten,
+		"day_count":    count.Days,
 	})
 }
 
 func (s *Server) CountEmotions(c echo.Context) error {
-	userID := s.GetUserID(c)
-	typ := c.QueryParam("type")
-	if typ == "" || (typ != "monthly" && typ != "yearly") {
-		return echo.NewHTTPError(http.StatusBadRequest, "타입이 잘못되었습니다.")
-	}
-	if c.QueryParam("date") == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "날짜를 입력해주세요.")
-	}
-	date, err := time.Parse("2006-01-02", c.QueryParam("date"))
+	p, err := s.parsePeriod(c)
 	if err != nil {
 		return err
 	}
-	emotions, err := s.ds.CountEmotions(c.Request().Context(), userID, typ, date)
+	emotions, err := s.ds.CountEmotions(c.Request().Context(), s.GetUserID(c), p)
 	if err != nil {
 		return err
 	}
@@ -270,3 +629,103 @@ func (s *Server) CountEmotions(c echo.Context) error {
 		"emotions": emotions,
 	})
 }
+
+func (s *Server) validateTheme(c echo.Context, theme string) error {
+	isThemeExists, err := s.ds.ThemeExists(c.Request().Context(), theme)
+	if err != nil {
+		return err
+	}
+	if !isThemeExists {
+		return echo.NewHTTPError(http.StatusBadRequest, "존재하지 않는 테마입니다.")
+	}
+	return nil
+}
+
+func (s *Server) validateEmotions(c echo.Context, emotions []string) error {
+	for _, emotion := range emotions {
+		isEmotionExists, err := s.ds.EmotionExists(c.Request().Context(), s.GetUserID(c), emotion)
+		if err != nil {
+			return err
+		}
+		if !isEmotionExists {
+			return echo.NewHTTPError(http.StatusBadRequest, "존재하지 않는 감정입니다.")
+		}
+	}
+	return nil
+}
+
+// diaryTime sets the time of day of a new diary. Users with a single diary
+// per day keep midnight unless a time is given, while users with multiple
+// entries get the current time so their entries stay in writing order.
+func (s *Server) diaryTime(c echo.Context, date time.Time, clock string) (time.Time, error) {
+	if clock != "" {
+		d, err := parseClock(clock)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Date(date.Year(), date.Month(), date.Day(), int(d/time.Hour), int(d%time.Hour/time.Minute), 0, 0, date.Location()), nil
+	}
+	multipleEntries, err := s.ds.MultipleEntries(c.Request().Context(), s.GetUserID(c))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !multipleEntries {
+		return date, nil
+	}
+	now := time.Now().In(date.Location())
+	return time.Date(date.Year(), date.Month(), date.Day(), now.Hour(), now.Minute(), now.Second(), 0, date.Location()), nil
+}
+
+// parseClock parses a time of day such as "21:30" as the time since midnight.
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "시간 형식이 올바르지 않습니다.")
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// location returns the time zone of the signed-in user. It is looked up once
+// per request.
+func (s *Server) location(c echo.Context) (*time.Location, error) {
+	if loc, ok := c.Get("location").(*time.Location); ok {
+		return loc, nil
+	}
+	loc, err := s.ds.Location(c.Request().Context(), s.GetUserID(c))
+	if err != nil {
+		return nil, err
+	}
+	c.Set("location", loc)
+	return loc, nil
+}
+
+// parseDate parses a date as the midnight that starts the day in the time
+// zone of the user.
+func (s *Server) parseDate(c echo.Context, value string) (time.Time, error) {
+	loc, err := s.location(c)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.ParseInLocation("2006-01-02", value, loc)
+}
+
+func (s *Server) diaryJSON(c echo.Context, diary model.Diary) error {
+	loc, err := s.location(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, newDiaryResponse(diary, loc))
+}
+
+func diaryUpdateError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "존재하지 않는 일기입니다.")
+	}
+	if errors.Is(err, service.ErrDiaryExists) {
+		return echo.NewHTTPError(http.StatusConflict, "해당 날짜에 이미 일기가 존재합니다.")
+	}
+	if errors.Is(err, service.ErrRetiredEmotion) {
+		return echo.NewHTTPError(http.StatusBadRequest, "더 이상 선택할 수 없는 감정입니다.")
+	}
+	return err
+}
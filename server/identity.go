@@ -0,0 +1,173 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/provider"
+	"dailyscoop-backend/repository"
+	"dailyscoop-backend/service"
+)
+
+// providerIdentity authenticates a request with the provider configured as
+// name. It returns the identity, the ID accounts of the identity were made
+// with before identities were linked, and the profile the provider gave.
+// Clients send ID tokens as the id_token parameter, the name of the user as
+// the name parameter when the provider leaves it out of its tokens, and
+// access tokens as the access_token parameter, or in the Authorization header
+// when the request is not authenticated otherwise.
+func (s *Server) providerIdentity(c echo.Context, name string, authorizationHeader bool) (model.Identity, string, model.User, error) {
+	p, err := s.providers.Provider(name)
+	if err != nil {
+		if errors.Is(err, provider.ErrUnknownProvider) {
+			return model.Identity{}, "", model.User{}, echo.NewHTTPError(http.StatusBadRequest, "지원하지 않는 로그인 방식입니다.")
+		}
+		return model.Identity{}, "", model.User{}, err
+	}
+	accessToken := c.QueryParam("access_token")
+	if accessToken == "" && authorizationHeader {
+		accessToken = strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+	}
+	identity, err := p.Authenticate(c.Request().Context(), provider.Credentials{
+		AccessToken: accessToken,
+		IDToken:     c.QueryParam("id_token"),
+		Name:        c.QueryParam("name"),
+	})
+	if err != nil {
+		if errors.Is(err, provider.ErrInvalidCredentials) {
+			return model.Identity{}, "", model.User{}, echo.NewHTTPError(http.StatusUnauthorized, "로그인 정보를 확인해주세요.")
+		}
+		return model.Identity{}, "", model.User{}, err
+	}
+	linked := model.Identity{
+		Provider: name,
+		Subject:  identity.Subject,
+	}
+	// Relay addresses are only good for mail, not for telling users apart.
+	if !identity.PrivateEmail {
+		linked.Email = identity.Email
+	}
+	profile := model.User{
+		Nickname:     identity.Nickname,
+		ProfileImage: identity.ProfileImage,
+	}
+	return linked, identity.LegacyID, profile, nil
+}
+
+func (s *Server) GetIdentities(c echo.Context) error {
+	user, err := s.us.UserByID(c.Request().Context(), s.GetUserID(c))
+	if err != nil {
+		return err
+	}
+	type identityResponse struct {
+		Provider string    `json:"provider"`
+		Email    string    `json:"email,omitempty"`
+		LinkedAt time.Time `json:"linked_at"`
+	}
+	resp := struct {
+		HasPassword bool               `json:"has_password"`
+		Identities  []identityResponse `json:"identities"`
+	}{
+		HasPassword: user.Password != "",
+		Identities:  []identityResponse{},
+	}
+	for _, identity := range user.Identities {
+		resp.Identities = append(resp.Identities, identityResponse{
+			Provider: identity.Provider,
+			Email:    identity.Email,
+			LinkedAt: identity.LinkedAt,
+		})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// LinkIdentity lets the user also sign in through the provider named by the
+// type parameter, with the credentials of the request.
+func (s *Server) LinkIdentity(c echo.Context) error {
+	identity, legacyID, _, err := s.providerIdentity(c, c.QueryParam("type"), false)
+	if err != nil {
+		return err
+	}
+	if err := s.us.LinkIdentity(c.Request().Context(), s.GetUserID(c), identity, legacyID); err != nil {
+		if errors.Is(err, service.ErrIdentityInUse) {
+			return echo.NewHTTPError(http.StatusConflict, "다른 계정에서 사용 중인 로그인입니다. 계정 합치기를 이용해주세요.")
+		}
+		if errors.Is(err, service.ErrProviderLinked) {
+			return echo.NewHTTPError(http.StatusConflict, "이미 연결된 로그인 방식입니다.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "로그인 방식을 연결했습니다.",
+	})
+}
+
+func (s *Server) UnlinkIdentity(c echo.Context) error {
+	if err := s.us.UnlinkIdentity(c.Request().Context(), s.GetUserID(c), c.Param("provider")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "연결되지 않은 로그인 방식입니다.")
+		}
+		if errors.Is(err, service.ErrLastSignIn) {
+			return echo.NewHTTPError(http.StatusBadRequest, "마지막 로그인 방식은 해제할 수 없습니다.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "로그인 방식 연결을 해제했습니다.",
+	})
+}
+
+// MergeAccount moves another account of the user into the one they are
+// signed in to, and deletes it. The request proves the other account is
+// theirs the way Login does: with the provider named by the type parameter,
+// or with its ID and password.
+func (s *Server) MergeAccount(c echo.Context) error {
+	ctx := c.Request().Context()
+	var other model.User
+	if typ := c.QueryParam("type"); typ != "" {
+		identity, legacyID, _, err := s.providerIdentity(c, typ, false)
+		if err != nil {
+			return err
+		}
+		other, err = s.us.UserByIdentity(ctx, identity, legacyID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return echo.NewHTTPError(http.StatusNotFound, "해당 로그인으로 가입한 계정이 없습니다. 로그인 방식 연결을 이용해주세요.")
+			}
+			return err
+		}
+	} else {
+		var req struct {
+			ID       string
+			Password string
+		}
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		var err error
+		other, err = s.passwordUser(c, req.ID, req.Password)
+		if err != nil {
+			return err
+		}
+	}
+	if err := s.ds.MergeUsers(ctx, s.GetUserID(c), other.ID); err != nil {
+		if errors.Is(err, service.ErrMergeSelf) {
+			return echo.NewHTTPError(http.StatusBadRequest, "이미 로그인한 계정입니다.")
+		}
+		if errors.Is(err, service.ErrProviderLinked) {
+			return echo.NewHTTPError(http.StatusConflict, "두 계정에 같은 로그인 방식이 연결되어 있습니다. 한쪽의 연결을 해제해주세요.")
+		}
+		if errors.Is(err, service.ErrMergeConflict) {
+			return echo.NewHTTPError(http.StatusConflict, "같은 날짜에 쓴 일기가 있습니다. 여러 개 작성하기를 켜고 다시 시도해주세요.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "계정을 합쳤습니다.",
+	})
+}
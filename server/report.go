@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"dailyscoop-backend/model"
+)
+
+type nameCountResponse struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func newNameCountResponses(counts []model.NameCount) []nameCountResponse {
+	resp := []nameCountResponse{}
+	for _, count := range counts {
+		resp = append(resp, nameCountResponse{Name: count.Name, Count: count.Count})
+	}
+	return resp
+}
+
+// GetYearlyReport returns the stored year in review. A report that is being
+// generated for the first time answers 202 with no content, and one that is
+// being regenerated is returned as it was with its status set to updating.
+func (s *Server) GetYearlyReport(c echo.Context) error {
+	loc, err := s.location(c)
+	if err != nil {
+		return err
+	}
+	year := time.Now().In(loc).Year()
+	if yearStr := c.QueryParam("year"); yearStr != "" {
+		year, err = strconv.Atoi(yearStr)
+		if err != nil || year < 1 || year > 9999 {
+			return echo.NewHTTPError(http.StatusBadRequest, "연도가 올바르지 않습니다.")
+		}
+	}
+	report, err := s.ds.YearlyReport(c.Request().Context(), s.GetUserID(c), year)
+	if err != nil {
+		return err
+	}
+	if !report.Generated() {
+		return c.JSON(http.StatusAccepted, echo.Map{
+			"year":   year,
+			"status": "generating",
+		})
+	}
+	status := "ready"
+	if !report.Fresh() {
+		status = "updating"
+	}
+	type Highlight struct {
+		DiaryID string `json:"diary_id"`
+		Date    string `json:"date"`
+		Image   string `json:"image"`
+	}
+	stats := report.Stats
+	highlights := []Highlight{}
+	for _, h := range stats.Highlights {
+		highlights = append(highlights, Highlight{
+			DiaryID: h.DiaryID,
+			Date:    h.Date.In(loc).Format("2006-01-02"),
+			Image:   h.Image,
+		})
+	}
+	var busiestMonth interface{}
+	if stats.BusiestMonth != 0 {
+		busiestMonth = echo.Map{
+			"month":   int(stats.BusiestMonth),
+			"entries": stats.BusiestMonthEntries,
+		}
+	}
+	var longestEntry interface{}
+	if stats.LongestEntry != "" {
+		longestEntry = echo.Map{
+			"id":     stats.LongestEntry,
+			"date":   stats.LongestEntryDate.In(loc).Format("2006-01-02"),
+			"length": stats.LongestEntryLength,
+		}
+	}
+	longestStreak := streakResponse{}
+	if stats.LongestStreak.Days > 0 {
+		longestStreak = streakResponse{
+			Days:  stats.LongestStreak.Days,
+			Start: stats.LongestStreak.Start.In(loc).Format("2006-01-02"),
+			End:   stats.LongestStreak.End.In(loc).Format("2006-01-02"),
+		}
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"year":           report.Year,
+		"status":         status,
+		"generated_at":   report.GeneratedAt,
+		"entry_count":    stats.Entries,
+		"day_count":      stats.Days,
+		"longest_streak": longestStreak,
+		"top_emotions":   newNameCountResponses(stats.TopEmotions),
+		"top_themes":     newNameCountResponses(stats.TopThemes),
+		"busiest_month":  busiestMonth,
+		"word_count":     stats.WordCount,
+		"longest_entry":  longestEntry,
+		"highlights":     highlights,
+	})
+}
+
+// RunReportGenerator generates the reports requested by the diary service
+// until ctx is done.
+func (s *Server) RunReportGenerator(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-s.ds.ReportRequests():
+			if err := s.ds.GenerateYearlyReport(ctx, req); err != nil {
+				s.Logger.Errorf("generate %d report of %s: %v", req.Year, req.UserID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+type revisionResponse struct {
+	ID        string    `json:"id"`
+	Content   string    `json:"content"`
+	Image     string    `json:"image"`
+	Emotions  []string  `json:"emotions"`
+	Theme     string    `json:"theme"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newRevisionResponse(revision model.DiaryRevision) revisionResponse {
+	return revisionResponse{
+		ID:        revision.ID,
+		Content:   revision.Content,
+		Image:     revision.Image,
+		Emotions:  revision.Emotions,
+		Theme:     revision.Theme,
+		CreatedAt: revision.CreatedAt,
+	}
+}
+
+func (s *Server) GetRevisions(c echo.Context) error {
+	revisions, err := s.ds.Revisions(c.Request().Context(), s.GetUserID(c), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "존재하지 않는 일기입니다.")
+		}
+		return err
+	}
+	resp := struct {
+		Revisions []revisionResponse `json:"revisions"`
+	}{
+		Revisions: []revisionResponse{},
+	}
+	for _, revision := range revisions {
+		resp.Revisions = append(resp.Revisions, newRevisionResponse(revision))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) GetRevision(c echo.Context) error {
+	revision, err := s.ds.Revision(c.Request().Context(), s.GetUserID(c), c.Param("id"), c.Param("revision"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "존재하지 않는 수정 기록입니다.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusOK, newRevisionResponse(revision))
+}
+
+func (s *Server) DiffRevisions(c echo.Context) error {
+	from := c.QueryParam("from")
+	if from == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "비교할 수정 기록을 선택해주세요.")
+	}
+	d, err := s.ds.DiffRevisions(c.Request().Context(), s.GetUserID(c), c.Param("id"), from, c.QueryParam("to"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "존재하지 않는 수정 기록입니다.")
+		}
+		return err
+	}
+	type Line struct {
+		Op   string `json:"op"`
+		Text string `json:"text"`
+	}
+	resp := struct {
+		From            revisionResponse `json:"from"`
+		To              revisionResponse `json:"to"`
+		Lines           []Line           `json:"lines"`
+		ImageChanged    bool             `json:"image_changed"`
+		EmotionsChanged bool             `json:"emotions_changed"`
+		ThemeChanged    bool             `json:"theme_changed"`
+	}{
+		From:            newRevisionResponse(d.From),
+		To:              newRevisionResponse(d.To),
+		Lines:           []Line{},
+		ImageChanged:    d.ImageChanged,
+		EmotionsChanged: d.EmotionsChanged,
+		ThemeChanged:    d.ThemeChanged,
+	}
+	for _, line := range d.Lines {
+		resp.Lines = append(resp.Lines, Line{
+			Op:   string(line.Op),
+			Text: line.Text,
+		})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) RestoreRevision(c echo.Context) error {
+	diary, err := s.ds.RestoreRevision(c.Request().Context(), s.GetUserID(c), c.Param("id"), c.Param("revision"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "존재하지 않는 수정 기록입니다.")
+		}
+		return err
+	}
+	return s.diaryJSON(c, diary)
+}
@@ -5,26 +5,29 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 
 	"dailyscoop-backend/config"
+	"dailyscoop-backend/provider"
 	"dailyscoop-backend/service"
 )
 
 type Server struct {
 	*echo.Echo
-	cfg config.Config
-	us  *service.UserService
-	ds  *service.DiaryService
-	fs  *service.FavoriteService
-	as  *service.AWSService
+	cfg       config.Config
+	us        *service.UserService
+	ds        *service.DiaryService
+	fs        *service.FavoriteService
+	as        *service.AWSService
+	providers *provider.Registry
 }
 
-func NewServer(cfg config.Config, us *service.UserService, ds *service.DiaryService, fs *service.FavoriteService, as *service.AWSService) *Server {
+func NewServer(cfg config.Config, us *service.UserService, ds *service.DiaryService, fs *service.FavoriteService, as *service.AWSService, providers *provider.Registry) *Server {
 	s := &Server{
-		Echo: echo.New(),
-		cfg:  cfg,
-		us:   us,
-		ds:   ds,
-		fs:   fs,
-		as:   as,
+		Echo:      echo.New(),
+		cfg:       cfg,
+		us:        us,
+		ds:        ds,
+		fs:        fs,
+		as:        as,
+		providers: providers,
 	}
 	s.Use(middleware.Logger())
 	s.Use(middleware.Recover())
@@ -33,42 +36,84 @@ func NewServer(cfg config.Config, us *service.UserService, ds *service.DiaryServ
 
 func (s *Server) RegisterRoutes() {
 	api := s.Group("/api")
+	auth := s.authenticate()
 
 	api.POST("/login", s.Login)
 	api.POST("/signup", s.SignUp)
 	api.POST("/image", s.ImageUpload)
+	api.POST("/token/refresh", s.RefreshToken)
+	api.POST("/logout", s.Logout, auth)
 
 	user := api.Group("/user")
-	user.Use(middleware.JWTWithConfig(middleware.JWTConfig{
-		Claims:     &jwtCustomClaims{},
-		SigningKey: []byte(s.cfg.Server.Secret),
-	}))
+	user.Use(auth)
 
 	user.GET("", s.GetUserInfo)
 	user.DELETE("", s.DeleteUser)
 	user.PUT("/change_password", s.ChangePassword)
 	user.PUT("/change_nickname", s.ChangeNickname)
 	user.PUT("/set_image", s.SetProfileImage)
+	user.PUT("/set_multiple_entries", s.SetMultipleEntries)
+	user.PUT("/set_timezone", s.SetTimezone)
+	user.POST("/logout_all", s.LogoutAll)
+	user.GET("/sessions", s.GetSessions)
+	user.DELETE("/sessions/:id", s.DeleteSession)
+	user.GET("/identities", s.GetIdentities)
+	user.POST("/identities", s.LinkIdentity)
+	user.DELETE("/identities/:provider", s.UnlinkIdentity)
+	user.POST("/merge", s.MergeAccount)
+	user.GET("/emotions", s.GetCustomEmotions)
+	user.POST("/emotions", s.AddCustomEmotion)
+	user.DELETE("/emotions/:name", s.DeleteCustomEmotion)
 
 	diaries := api.Group("/diaries")
-	diaries.Use(middleware.JWTWithConfig(middleware.JWTConfig{
-		Claims:     &jwtCustomClaims{},
-		SigningKey: []byte(s.cfg.Server.Secret),
-	}))
+	diaries.Use(auth)
 
 	diaries.GET("", s.GetAllDiaries)
 	diaries.GET("/calendar", s.GetCalendar)
+	diaries.GET("/memories", s.GetMemories)
+	diaries.GET("/trash", s.GetTrash)
+	diaries.POST("/trash/:id/restore", s.RestoreDiary)
+	diaries.DELETE("/trash/:id", s.DeleteTrashedDiary)
 	diaries.POST("", s.CreateDiary)
 	diaries.GET("/:date", s.GetDiary)
+	diaries.PATCH("/:date", s.PatchDiaryByDate)
 	diaries.DELETE("/:date", s.DeleteDiary)
+	diaries.GET("/id/:id", s.GetDiaryByID)
+	diaries.PUT("/id/:id", s.UpdateDiary)
+	diaries.PATCH("/id/:id", s.PatchDiary)
+	diaries.DELETE("/id/:id", s.DeleteDiaryByID)
+	diaries.GET("/id/:id/revisions", s.GetRevisions)
+	diaries.GET("/id/:id/revisions/diff", s.DiffRevisions)
+	diaries.GET("/id/:id/revisions/:revision", s.GetRevision)
+	diaries.POST("/id/:id/revisions/:revision/restore", s.RestoreRevision)
 	diaries.GET("/count", s.CountDiaries)
+	diaries.GET("/streaks", s.GetStreaks)
 	diaries.GET("/emotions", s.CountEmotions)
+	diaries.GET("/emotions/trend", s.GetEmotionTrend)
+	diaries.GET("/emotions/cooccurrence", s.GetEmotionMatrix)
+	diaries.GET("/emotions/weekdays", s.GetEmotionsByWeekday)
+	diaries.GET("/emotions/themes", s.GetEmotionsByTheme)
+	diaries.GET("/themes", s.CountThemes)
+
+	reports := api.Group("/reports")
+	reports.Use(auth)
+
+	reports.GET("/yearly", s.GetYearlyReport)
+
+	api.GET("/emotions", s.GetEmotions)
+	api.GET("/themes", s.GetThemes)
+
+	admin := api.Group("/admin")
+	admin.Use(auth)
+	admin.Use(s.requireAdmin)
+
+	admin.POST("/emotions", s.AddEmotion)
+	admin.PATCH("/emotions/:name", s.PatchEmotion)
+	admin.POST("/emotions/:name/retire", s.RetireEmotion)
+	admin.PUT("/emotions/order", s.ReorderEmotions)
 
 	favorites := api.Group("/favorites")
-	favorites.Use(middleware.JWTWithConfig(middleware.JWTConfig{
-		Claims:     &jwtCustomClaims{},
-		SigningKey: []byte(s.cfg.Server.Secret),
-	}))
+	favorites.Use(auth)
 
 	favorites.GET("", s.GetFavorites)
 	favorites.POST("", s.AddFavorite)
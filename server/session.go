@@ -0,0 +1,179 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+	"dailyscoop-backend/service"
+)
+
+// accessToken signs a short-lived token for the session, carrying the current
+// token version of the user.
+func (s *Server) accessToken(c echo.Context, session model.Session) (string, error) {
+	user, err := s.us.UserByID(c.Request().Context(), session.UserID)
+	if err != nil {
+		return "", err
+	}
+	claims := &jwtCustomClaims{
+		ID:        session.UserID,
+		SessionID: session.ID,
+		Version:   user.TokenVersion,
+		StandardClaims: jwt.StandardClaims{
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: time.Now().Add(s.cfg.Server.AccessTokenTTL).Unix(),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(s.cfg.Server.Secret))
+}
+
+// deviceName names the device a client signs in from, from the X-Device-Name
+// header it may send or its user agent.
+func deviceName(c echo.Context) string {
+	if name := c.Request().Header.Get("X-Device-Name"); name != "" {
+		return name
+	}
+	return c.Request().UserAgent()
+}
+
+func (s *Server) RefreshToken(c echo.Context) error {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if req.RefreshToken == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "파라미터가 올바르지 않습니다.")
+	}
+	session, refreshToken, err := s.us.RefreshSession(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "다시 로그인해주세요.")
+		}
+		if errors.Is(err, service.ErrRefreshTokenReused) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "이미 사용된 토큰입니다. 다시 로그인해주세요.")
+		}
+		return err
+	}
+	t, err := s.accessToken(c, session)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "다시 로그인해주세요.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"token":         t,
+		"refresh_token": refreshToken,
+		"expires_in":    int(s.cfg.Server.AccessTokenTTL.Seconds()),
+	})
+}
+
+// Logout ends the session of the access token.
+func (s *Server) Logout(c echo.Context) error {
+	if err := s.us.DeleteSession(c.Request().Context(), s.GetUserID(c), s.GetSessionID(c)); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "로그아웃했습니다.",
+	})
+}
+
+// LogoutAll signs the user out on every device, this one included.
+func (s *Server) LogoutAll(c echo.Context) error {
+	if err := s.us.RevokeTokens(c.Request().Context(), s.GetUserID(c)); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "모든 기기에서 로그아웃했습니다.",
+	})
+}
+
+// authenticate accepts requests whose access token is valid, carries the
+// current token version of its user and belongs to a session that has not
+// ended. Tokens issued before sessions existed have no session, so they
+// cannot be revoked and are not accepted; their users sign in again. Neither
+// are tokens issued before the user was created, which belong to a deleted
+// user who had the same ID.
+func (s *Server) authenticate() echo.MiddlewareFunc {
+	verify := middleware.JWTWithConfig(middleware.JWTConfig{
+		Claims:     &jwtCustomClaims{},
+		SigningKey: []byte(s.cfg.Server.Secret),
+	})
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return verify(func(c echo.Context) error {
+			user, err := s.us.UserByID(c.Request().Context(), s.GetUserID(c))
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return echo.NewHTTPError(http.StatusUnauthorized, "다시 로그인해주세요.")
+				}
+				return err
+			}
+			claims := c.Get("user").(*jwt.Token).Claims.(*jwtCustomClaims)
+			if claims.Version != user.TokenVersion || claims.IssuedAt < user.CreatedAt.Unix() {
+				return echo.NewHTTPError(http.StatusUnauthorized, "다시 로그인해주세요.")
+			}
+			if claims.SessionID == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "다시 로그인해주세요.")
+			}
+			active, err := s.us.SessionActive(c.Request().Context(), user.ID, claims.SessionID)
+			if err != nil {
+				return err
+			}
+			if !active {
+				return echo.NewHTTPError(http.StatusUnauthorized, "다시 로그인해주세요.")
+			}
+			return next(c)
+		})
+	}
+}
+
+func (s *Server) GetSessions(c echo.Context) error {
+	sessions, err := s.us.Sessions(c.Request().Context(), s.GetUserID(c))
+	if err != nil {
+		return err
+	}
+	type sessionResponse struct {
+		ID         string    `json:"id"`
+		Device     string    `json:"device"`
+		CreatedAt  time.Time `json:"created_at"`
+		LastSeenAt time.Time `json:"last_seen_at"`
+		Current    bool      `json:"current"`
+	}
+	current := s.GetSessionID(c)
+	resp := struct {
+		Sessions []sessionResponse `json:"sessions"`
+	}{
+		Sessions: []sessionResponse{},
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, sessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == current,
+		})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) DeleteSession(c echo.Context) error {
+	if err := s.us.DeleteSession(c.Request().Context(), s.GetUserID(c), c.Param("id")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "존재하지 않는 세션입니다.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "해당 기기에서 로그아웃했습니다.",
+	})
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"dailyscoop-backend/period"
+	"dailyscoop-backend/service"
+)
+
+type streakResponse struct {
+	Days  int    `json:"days"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+func newStreakResponse(streak service.Streak) streakResponse {
+	if streak.Days == 0 {
+		return streakResponse{}
+	}
+	return streakResponse{
+		Days:  streak.Days,
+		Start: streak.Start.Format("2006-01-02"),
+		End:   streak.End.Format("2006-01-02"),
+	}
+}
+
+func (s *Server) GetStreaks(c echo.Context) error {
+	stats, err := s.ds.Streaks(c.Request().Context(), s.GetUserID(c), time.Now())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"current_streak":   newStreakResponse(stats.Current),
+		"longest_streak":   newStreakResponse(stats.Longest),
+		"written_today":    stats.WrittenToday,
+		"entries_per_week": stats.EntriesPerWeek,
+	})
+}
+
+// trendIntervals maps the interval query parameter to period units.
+var trendIntervals = map[string]period.Unit{
+	"day":     period.Daily,
+	"week":    period.Weekly,
+	"month":   period.Monthly,
+	"quarter": period.Quarterly,
+	"year":    period.Yearly,
+}
+
+// maxTrendPeriods bounds the length of the series.
+const maxTrendPeriods = 1000
+
+func (s *Server) GetEmotionTrend(c echo.Context) error {
+	interval := c.QueryParam("interval")
+	if interval == "" {
+		interval = "day"
+	}
+	unit, ok := trendIntervals[interval]
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "interval은 day, week, month, quarter 또는 year여야 합니다.")
+	}
+	weekStart, err := period.ParseWeekStart(c.QueryParam("week_start"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "week_start는 sunday, monday 또는 iso여야 합니다.")
+	}
+	if c.QueryParam("from") == "" || c.QueryParam("to") == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "날짜를 입력해주세요.")
+	}
+	from, err := s.parseDate(c, c.QueryParam("from"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "날짜 형식이 올바르지 않습니다.")
+	}
+	to, err := s.parseDate(c, c.QueryParam("to"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "날짜 형식이 올바르지 않습니다.")
+	}
+	p, err := period.Range(from, to)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "시작 날짜가 종료 날짜보다 늦습니다.")
+	}
+	count, err := p.Count(unit, weekStart)
+	if err != nil {
+		return err
+	}
+	if count > maxTrendPeriods {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("기간이 너무 깁니다. 최대 %d개 구간까지 조회할 수 있습니다.", maxTrendPeriods))
+	}
+	window := 0
+	if windowStr := c.QueryParam("moving_average"); windowStr != "" {
+		window, err = strconv.Atoi(windowStr)
+		if err != nil || window < 1 || window > maxTrendPeriods {
+			return echo.NewHTTPError(http.StatusBadRequest, "moving_average가 올바르지 않습니다.")
+		}
+	}
+	trend, err := s.ds.EmotionTrend(c.Request().Context(), s.GetUserID(c), p, unit, weekStart, window)
+	if err != nil {
+		return err
+	}
+	type Period struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	}
+	resp := struct {
+		Interval       string               `json:"interval"`
+		Periods        []Period             `json:"periods"`
+		Emotions       map[string][]int     `json:"emotions"`
+		MovingAverages map[string][]float64 `json:"moving_averages,omitempty"`
+	}{
+		Interval:       interval,
+		Periods:        []Period{},
+		Emotions:       trend.Counts,
+		MovingAverages: trend.MovingAverages,
+	}
+	for _, q := range trend.Periods {
+		resp.Periods = append(resp.Periods, Period{
+			Start: q.Start.Format("2006-01-02"),
+			End:   q.End.AddDate(0, 0, -1).Format("2006-01-02"),
+		})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) CountThemes(c echo.Context) error {
+	p, err := s.parsePeriod(c)
+	if err != nil {
+		return err
+	}
+	themes, err := s.ds.CountThemes(c.Request().Context(), s.GetUserID(c), p)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"themes": themes,
+	})
+}
+
+func (s *Server) GetEmotionMatrix(c echo.Context) error {
+	p, err := s.parsePeriod(c)
+	if err != nil {
+		return err
+	}
+	matrix, err := s.ds.EmotionMatrix(c.Request().Context(), s.GetUserID(c), p)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"emotions": matrix.Emotions,
+		"matrix":   matrix.Counts,
+	})
+}
+
+func (s *Server) GetEmotionsByWeekday(c echo.Context) error {
+	p, err := s.parsePeriod(c)
+	if err != nil {
+		return err
+	}
+	weekdays, err := s.ds.EmotionsByWeekday(c.Request().Context(), s.GetUserID(c), p)
+	if err != nil {
+		return err
+	}
+	resp := make(map[string]map[string]int, len(weekdays))
+	for i, counts := range weekdays {
+		resp[strings.ToLower(time.Weekday(i).String())] = counts
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"weekdays": resp,
+	})
+}
+
+func (s *Server) GetEmotionsByTheme(c echo.Context) error {
+	p, err := s.parsePeriod(c)
+	if err != nil {
+		return err
+	}
+	themes, err := s.ds.EmotionsByTheme(c.Request().Context(), s.GetUserID(c), p)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"themes": themes,
+	})
+}
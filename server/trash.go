@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"dailyscoop-backend/repository"
+)
+
+func (s *Server) GetTrash(c echo.Context) error {
+	diaries, err := s.ds.TrashedDiaries(c.Request().Context(), s.GetUserID(c))
+	if err != nil {
+		return err
+	}
+	loc, err := s.location(c)
+	if err != nil {
+		return err
+	}
+	type Diary struct {
+		diaryResponse
+		DeletedAt time.Time `json:"deleted_at"`
+		PurgeAt   time.Time `json:"purge_at"`
+	}
+	resp := struct {
+		Diaries []Diary `json:"diaries"`
+	}{
+		Diaries: []Diary{},
+	}
+	for _, diary := range diaries {
+		resp.Diaries = append(resp.Diaries, Diary{
+			diaryResponse: newDiaryResponse(diary, loc),
+			DeletedAt:     *diary.DeletedAt,
+			PurgeAt:       diary.DeletedAt.Add(s.cfg.Trash.Retention),
+		})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) RestoreDiary(c echo.Context) error {
+	diary, err := s.ds.RestoreDiary(c.Request().Context(), s.GetUserID(c), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "휴지통에 존재하지 않는 일기입니다.")
+		}
+		return diaryUpdateError(err)
+	}
+	return s.diaryJSON(c, diary)
+}
+
+func (s *Server) DeleteTrashedDiary(c echo.Context) error {
+	if err := s.ds.DeleteTrashedDiary(c.Request().Context(), s.GetUserID(c), c.Param("id")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "휴지통에 존재하지 않는 일기입니다.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "일기를 완전히 삭제했습니다.",
+	})
+}
+
+// RunTrashPurger purges expired diaries from the trash every purge interval
+// until ctx is done.
+func (s *Server) RunTrashPurger(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Trash.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		n, err := s.ds.PurgeTrash(ctx, time.Now().Add(-s.cfg.Trash.Retention))
+		if err != nil {
+			s.Logger.Errorf("purge trash: %v", err)
+		} else if n > 0 {
+			s.Logger.Infof("purged %d diaries from the trash", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,27 +1,29 @@
 package server
 
 import (
-	"encoding/json"
 	"errors"
-	"io/ioutil"
 	"net/http"
-	"os"
-	"strconv"
-	"time"
 
 	"github.com/golang-jwt/jwt"
-	"github.com/mitchellh/mapstructure"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
-	"google.golang.org/api/idtoken"
 
 	"github.com/labstack/echo/v4"
 
 	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+	"dailyscoop-backend/service"
 )
 
+// defaultNickname is given to users who sign up through a provider that does
+// not tell their name.
+const defaultNickname = "다이어리 사용자"
+
 type jwtCustomClaims struct {
 	ID string `json:"id"`
+	// SessionID is the session the token was issued for.
+	SessionID string `json:"sid,omitempty"`
+	// Version is the token version of the user when the token was issued.
+	Version int `json:"ver,omitempty"`
 	jwt.StandardClaims
 }
 
@@ -32,23 +34,24 @@ func (s *Server) GetUserID(c echo.Context) string {
 	return id
 }
 
+// GetSessionID returns the session of the access token, or "" for tokens
+// issued before sessions existed.
+func (s *Server) GetSessionID(c echo.Context) string {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(*jwtCustomClaims)
+	return claims.SessionID
+}
+
 func (s *Server) Login(c echo.Context) error {
 	typ := c.QueryParam("type")
 	var user model.User
-	if typ == "kakao" {
+	if typ != "" {
 		var err error
-		user, err = s.KakaoLogin(c)
+		user, err = s.SocialLogin(c, typ)
 		if err != nil {
 			return err
 		}
-	} else if typ == "google" {
-		var err error
-		user, err = s.GoogleLogin(c)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-		}
 	} else {
-		var err error
 		var req struct {
 			ID       string
 			Password string
@@ -56,115 +59,59 @@ func (s *Server) Login(c echo.Context) error {
 		if err := c.Bind(&req); err != nil {
 			return err
 		}
-		user, err = s.us.UserByID(c.Request().Context(), req.ID)
+		var err error
+		user, err = s.passwordUser(c, req.ID, req.Password)
 		if err != nil {
-			if errors.Is(err, mongo.ErrNoDocuments) {
-				return echo.NewHTTPError(http.StatusUnauthorized, "아이디나 비밀번호를 확인해주세요.")
-			}
 			return err
 		}
-		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-			return echo.NewHTTPError(http.StatusUnauthorized, "아이디나 비밀번호를 확인해주세요.")
-		}
 	}
 
-	claims := &jwtCustomClaims{
-		user.ID,
-		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Hour * 720).Unix(),
-		},
+	session, refreshToken, err := s.us.CreateSession(c.Request().Context(), user.ID, deviceName(c))
+	if err != nil {
+		return err
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	t, err := token.SignedString([]byte(s.cfg.Server.Secret))
+	t, err := s.accessToken(c, session)
 	if err != nil {
 		return err
 	}
 
 	return c.JSON(http.StatusOK, echo.Map{
-		"token":    t,
-		"nickname": user.Nickname,
+		"token":         t,
+		"refresh_token": refreshToken,
+		"expires_in":    int(s.cfg.Server.AccessTokenTTL.Seconds()),
+		"nickname":      user.Nickname,
 	})
 }
 
-func (s *Server) KakaoLogin(c echo.Context) (model.User, error) {
-	token := "Bearer " + c.Request().Header.Get("Authorization")
-	req, err := http.NewRequest("GET", "https://kapi.kakao.com/v2/user/me", nil)
+// SocialLogin signs in through the provider configured as name, registering
+// users who sign in for the first time.
+func (s *Server) SocialLogin(c echo.Context, name string) (model.User, error) {
+	identity, legacyID, profile, err := s.providerIdentity(c, name, true)
 	if err != nil {
 		return model.User{}, err
 	}
-	req.Header.Add("Authorization", token)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return model.User{}, err
+	user, err := s.us.UserByIdentity(c.Request().Context(), identity, legacyID)
+	if err == nil || !errors.Is(err, repository.ErrNotFound) {
+		return user, err
 	}
-	defer resp.Body.Close()
-	var result struct {
-		ID          int
-		ConnectedAt string `json:"connected_at"`
-		Properties  struct {
-			Nickname string
-		}
-		Message string `json:"msg"`
-		Code    int
+	if profile.Nickname == "" {
+		// Users can change it once they are in.
+		profile.Nickname = defaultNickname
 	}
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return model.User{}, err
-	}
-	if err = json.Unmarshal(b, &result); err != nil {
-		return model.User{}, err
-	}
-	if resp.StatusCode != 200 {
-		return model.User{}, echo.NewHTTPError(resp.StatusCode, result.Message)
-	}
-	user, err := s.us.UserByID(c.Request().Context(), strconv.Itoa(result.ID))
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			user = model.User{
-				ID:       strconv.Itoa(result.ID),
-				Nickname: result.Properties.Nickname,
-			}
-			if err := s.us.RegisterUser(c.Request().Context(), user); err != nil {
-				return model.User{}, err
-			}
-		} else {
-			return model.User{}, err
-		}
-	}
-	return user, nil
+	return s.us.RegisterWithIdentity(c.Request().Context(), profile, identity)
 }
 
-func (s *Server) GoogleLogin(c echo.Context) (model.User, error) {
-	idToken := c.QueryParam("id_token")
-	v, err := idtoken.Validate(c.Request().Context(), idToken, os.Getenv("GOOGLE_KEY"))
+// passwordUser returns the user with the ID and password.
+func (s *Server) passwordUser(c echo.Context, id string, password string) (model.User, error) {
+	user, err := s.us.UserByID(c.Request().Context(), id)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.User{}, echo.NewHTTPError(http.StatusUnauthorized, "아이디나 비밀번호를 확인해주세요.")
+		}
 		return model.User{}, err
 	}
-	var tokenInfo struct {
-		Email   string
-		Name    string
-		Picture string
-	}
-	if err := mapstructure.Decode(v.Claims, &tokenInfo); err != nil {
-		return model.User{}, err
-	}
-	user, err := s.us.UserByID(c.Request().Context(), tokenInfo.Email)
-	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			user = model.User{
-				ID:           tokenInfo.Email,
-				Nickname:     tokenInfo.Name,
-				ProfileImage: tokenInfo.Picture,
-			}
-			if err := s.us.RegisterUser(c.Request().Context(), user); err != nil {
-				return model.User{}, err
-			}
-		} else {
-			return model.User{}, err
-		}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return model.User{}, echo.NewHTTPError(http.StatusUnauthorized, "아이디나 비밀번호를 확인해주세요.")
 	}
 	return user, nil
 }
@@ -183,14 +130,14 @@ func (s *Server) SignUp(c echo.Context) error {
 	}
 	ctx := c.Request().Context()
 	_, err := s.us.UserByID(ctx, req.ID)
-	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
 		return err
 	} else if err == nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "이미 존재하는 아이디입니다.")
 	}
 
 	_, err = s.us.UserByNickname(ctx, req.Nickname)
-	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
 		return err
 	} else if err == nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "이미 존재하는 닉네임입니다.")
@@ -201,6 +148,9 @@ func (s *Server) SignUp(c echo.Context) error {
 		Password: req.Password,
 		Nickname: req.Nickname,
 	}); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return echo.NewHTTPError(http.StatusBadRequest, "이미 존재하는 아이디입니다.")
+		}
 		return err
 	}
 
@@ -211,7 +161,7 @@ func (s *Server) SignUp(c echo.Context) error {
 
 func (s *Server) DeleteUser(c echo.Context) error {
 	if err := s.us.DeleteUser(c.Request().Context(), s.GetUserID(c)); err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
+		if errors.Is(err, repository.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusBadRequest, "존재하지 않는 유저입니다.")
 		}
 		return err
@@ -261,28 +211,49 @@ func (s *Server) ChangePassword(c echo.Context) error {
 	if err := s.us.UpdatePassword(c.Request().Context(), userID, req.NewPassword); err != nil {
 		return err
 	}
+	// Changing the password signs the user out everywhere, so this device
+	// gets a new session.
+	session, refreshToken, err := s.us.CreateSession(c.Request().Context(), userID, deviceName(c))
+	if err != nil {
+		return err
+	}
+	t, err := s.accessToken(c, session)
+	if err != nil {
+		return err
+	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "비밀번호가 변경되었습니다.",
+		"message":       "비밀번호가 변경되었습니다.",
+		"token":         t,
+		"refresh_token": refreshToken,
+		"expires_in":    int(s.cfg.Server.AccessTokenTTL.Seconds()),
 	})
 }
 
 func (s *Server) GetUserInfo(c echo.Context) error {
 	user, err := s.us.UserByID(c.Request().Context(), s.GetUserID(c))
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
+		if errors.Is(err, repository.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusBadRequest, "존재하지 않는 유저입니다.")
 		}
 		return err
 	}
 	type resp struct {
-		ID           string `json:"id"`
-		Nickname     string `json:"nickname"`
-		ProfileImage string `json:"profile_image"`
+		ID              string `json:"id"`
+		Nickname        string `json:"nickname"`
+		ProfileImage    string `json:"profile_image"`
+		MultipleEntries bool   `json:"multiple_entries"`
+		Timezone        string `json:"timezone"`
+	}
+	timezone := user.Timezone
+	if timezone == "" {
+		timezone = s.cfg.Server.Timezone
 	}
 	return c.JSON(http.StatusOK, resp{
-		ID:           user.ID,
-		Nickname:     user.Nickname,
-		ProfileImage: user.ProfileImage,
+		ID:              user.ID,
+		Nickname:        user.Nickname,
+		ProfileImage:    user.ProfileImage,
+		MultipleEntries: user.MultipleEntries,
+		Timezone:        timezone,
 	})
 }
 
@@ -303,3 +274,42 @@ func (s *Server) SetProfileImage(c echo.Context) error {
 		"message": "프로필 사진을 변경하였습니다.",
 	})
 }
+
+func (s *Server) SetMultipleEntries(c echo.Context) error {
+	var req struct {
+		MultipleEntries *bool
+	}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if req.MultipleEntries == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "파라미터가 올바르지 않습니다.")
+	}
+	if err := s.us.UpdateMultipleEntries(c.Request().Context(), s.GetUserID(c), *req.MultipleEntries); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "일기 작성 방식을 변경했습니다.",
+	})
+}
+
+func (s *Server) SetTimezone(c echo.Context) error {
+	var req struct {
+		Timezone string
+	}
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if req.Timezone == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "파라미터가 올바르지 않습니다.")
+	}
+	if err := s.us.UpdateTimezone(c.Request().Context(), s.GetUserID(c), req.Timezone); err != nil {
+		if errors.Is(err, service.ErrInvalidTimezone) {
+			return echo.NewHTTPError(http.StatusBadRequest, "존재하지 않는 시간대입니다.")
+		}
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "시간대를 변경했습니다.",
+	})
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+var (
+	ErrEmotionExists   = errors.New("service: emotion already exists")
+	ErrRetiredEmotion  = errors.New("service: emotion is retired")
+	ErrInvalidValence  = errors.New("service: invalid valence")
+	ErrIncompleteOrder = errors.New("service: order does not list every emotion once")
+)
+
+// Emotions returns the emotion catalog in display order, retired emotions
+// included.
+func (ds *DiaryService) Emotions(ctx context.Context) ([]model.Emotion, error) {
+	return ds.repo.Emotions.FindAll(ctx)
+}
+
+// Themes returns the theme catalog, including the seasonal themes that are
+// not available now.
+func (ds *DiaryService) Themes(ctx context.Context) ([]model.Theme, error) {
+	return ds.repo.Themes.FindAll(ctx)
+}
+
+// CustomEmotions returns the custom emotions of the user, oldest first.
+func (ds *DiaryService) CustomEmotions(ctx context.Context, userID string) ([]model.CustomEmotion, error) {
+	return ds.repo.CustomEmotions.FindByUserID(ctx, userID)
+}
+
+// AddCustomEmotion makes an emotion for the user. Its name cannot be taken by
+// the catalog, retired emotions included, or by another custom emotion of the
+// user.
+func (ds *DiaryService) AddCustomEmotion(ctx context.Context, emotion model.CustomEmotion) (model.CustomEmotion, error) {
+	if emotion.Valence != model.ValencePositive && emotion.Valence != model.ValenceNegative {
+		return model.CustomEmotion{}, ErrInvalidValence
+	}
+	exists, err := ds.EmotionExists(ctx, emotion.UserID, emotion.Name)
+	if err != nil {
+		return model.CustomEmotion{}, err
+	}
+	if exists {
+		return model.CustomEmotion{}, ErrEmotionExists
+	}
+	emotion.CreatedAt = time.Now()
+	if err := ds.repo.CustomEmotions.Insert(ctx, emotion); err != nil {
+		return model.CustomEmotion{}, err
+	}
+	return emotion, nil
+}
+
+// DeleteCustomEmotion deletes a custom emotion of the user. Diaries that have
+// it keep it.
+func (ds *DiaryService) DeleteCustomEmotion(ctx context.Context, userID string, name string) error {
+	exists, err := ds.repo.CustomEmotions.Exists(ctx, userID, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return repository.ErrNotFound
+	}
+	return ds.repo.CustomEmotions.Delete(ctx, userID, name)
+}
+
+// AddEmotion adds an emotion at the end of the catalog.
+func (ds *DiaryService) AddEmotion(ctx context.Context, emotion model.Emotion) (model.Emotion, error) {
+	if emotion.Valence != model.ValencePositive && emotion.Valence != model.ValenceNegative {
+		return model.Emotion{}, ErrInvalidValence
+	}
+	exists, err := ds.repo.Emotions.Exists(ctx, emotion.Name)
+	if err != nil {
+		return model.Emotion{}, err
+	}
+	if exists {
+		return model.Emotion{}, ErrEmotionExists
+	}
+	all, err := ds.repo.Emotions.FindAll(ctx)
+	if err != nil {
+		return model.Emotion{}, err
+	}
+	emotion.Order = 0
+	for _, e := range all {
+		if e.Order >= emotion.Order {
+			emotion.Order = e.Order + 1
+		}
+	}
+	emotion.RetiredAt = nil
+	if err := ds.repo.Emotions.Insert(ctx, emotion); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return model.Emotion{}, ErrEmotionExists
+		}
+		return model.Emotion{}, err
+	}
+	return emotion, nil
+}
+
+// EmotionPatch changes the metadata of an emotion of the catalog. Nil fields
+// are left as they are.
+type EmotionPatch struct {
+	Emoji   *string
+	Color   *string
+	Valence *string
+}
+
+// PatchEmotion changes the emoji, color or valence of an emotion, such as
+// the emotions that were stored with a name only.
+func (ds *DiaryService) PatchEmotion(ctx context.Context, name string, patch EmotionPatch) (model.Emotion, error) {
+	if patch.Valence != nil && *patch.Valence != model.ValencePositive && *patch.Valence != model.ValenceNegative {
+		return model.Emotion{}, ErrInvalidValence
+	}
+	emotion, err := ds.repo.Emotions.FindByName(ctx, name)
+	if err != nil {
+		return model.Emotion{}, err
+	}
+	if patch.Emoji != nil {
+		emotion.Emoji = *patch.Emoji
+	}
+	if patch.Color != nil {
+		emotion.Color = *patch.Color
+	}
+	if patch.Valence != nil {
+		emotion.Valence = *patch.Valence
+	}
+	if err := ds.repo.Emotions.Update(ctx, emotion); err != nil {
+		return model.Emotion{}, err
+	}
+	return emotion, nil
+}
+
+// RetireEmotion keeps an emotion from being chosen for new diaries. Diaries
+// that already have it keep it.
+func (ds *DiaryService) RetireEmotion(ctx context.Context, name string) error {
+	emotion, err := ds.repo.Emotions.FindByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if emotion.Retired() {
+		return nil
+	}
+	return ds.repo.Emotions.Retire(ctx, name, time.Now())
+}
+
+// ReorderEmotions sets the display order of the catalog. Names has to list
+// every emotion exactly once, retired ones included.
+func (ds *DiaryService) ReorderEmotions(ctx context.Context, names []string) error {
+	all, err := ds.repo.Emotions.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	if len(names) != len(all) {
+		return ErrIncompleteOrder
+	}
+	listed := make(map[string]bool, len(names))
+	for _, name := range names {
+		listed[name] = true
+	}
+	for _, emotion := range all {
+		if !listed[emotion.Name] {
+			return ErrIncompleteOrder
+		}
+	}
+	return ds.repo.Emotions.Reorder(ctx, names)
+}
+
+// checkRetired fails with ErrRetiredEmotion when emotions has a retired
+// emotion that is not among the current emotions of the diary.
+func (ds *DiaryService) checkRetired(ctx context.Context, emotions []string, current []string) error {
+	allowed := make(map[string]bool, len(current))
+	for _, name := range current {
+		allowed[name] = true
+	}
+	for _, name := range emotions {
+		if allowed[name] {
+			continue
+		}
+		emotion, err := ds.repo.Emotions.FindByName(ctx, name)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if emotion.Retired() {
+			return ErrRetiredEmotion
+		}
+	}
+	return nil
+}
+
+// activeEmotions returns the emotions of the catalog that are not retired in
+// display order, followed by the custom emotions of the user.
+func (ds *DiaryService) activeEmotions(ctx context.Context, userID string) ([]model.Emotion, error) {
+	all, err := ds.repo.Emotions.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	custom, err := ds.repo.CustomEmotions.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	active := all[:0]
+	seen := make(map[string]bool, len(all))
+	for _, emotion := range all {
+		seen[emotion.Name] = true
+		if !emotion.Retired() {
+			active = append(active, emotion)
+		}
+	}
+	for _, emotion := range custom {
+		// A custom emotion may share its name with one added to the catalog
+		// after it was made.
+		if seen[emotion.Name] {
+			continue
+		}
+		active = append(active, model.Emotion{
+			Name:    emotion.Name,
+			Emoji:   emotion.Emoji,
+			Valence: emotion.Valence,
+		})
+	}
+	return active, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"dailyscoop-backend/config"
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/period"
+	"dailyscoop-backend/repository"
+	"dailyscoop-backend/repository/memory"
+)
+
+func TestCustomEmotions(t *testing.T) {
+	ctx := context.Background()
+	ds := NewDiaryService(memory.New(config.MemoryConfig{
+		Emotions: []string{"기쁨", "슬픔"},
+	}), time.UTC)
+	if _, err := ds.AddCustomEmotion(ctx, model.CustomEmotion{
+		UserID: "user", Name: "설렘", Emoji: "🥰", Valence: model.ValencePositive,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.AddCustomEmotion(ctx, model.CustomEmotion{
+		UserID: "user", Name: "기쁨", Emoji: "😀", Valence: model.ValencePositive,
+	}); !errors.Is(err, ErrEmotionExists) {
+		t.Errorf("adding a catalog emotion: got %v, want ErrEmotionExists", err)
+	}
+	if _, err := ds.AddCustomEmotion(ctx, model.CustomEmotion{
+		UserID: "user", Name: "권태", Emoji: "😑", Valence: "neutral",
+	}); !errors.Is(err, ErrInvalidValence) {
+		t.Errorf("adding with an unknown valence: got %v, want ErrInvalidValence", err)
+	}
+
+	for _, tt := range []struct {
+		userID string
+		want   bool
+	}{
+		{"user", true},
+		{"other", false},
+	} {
+		exists, err := ds.EmotionExists(ctx, tt.userID, "설렘")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists != tt.want {
+			t.Errorf("EmotionExists(%q, 설렘) = %v, want %v", tt.userID, exists, tt.want)
+		}
+	}
+
+	p := period.Period{
+		Start: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for _, tt := range []struct {
+		userID string
+		want   map[string]int
+	}{
+		{"user", map[string]int{"기쁨": 0, "슬픔": 0, "설렘": 0}},
+		{"other", map[string]int{"기쁨": 0, "슬픔": 0}},
+	} {
+		counts, err := ds.CountEmotions(ctx, tt.userID, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(counts) != len(tt.want) {
+			t.Errorf("CountEmotions(%q) = %v, want %v", tt.userID, counts, tt.want)
+			continue
+		}
+		for name, count := range tt.want {
+			if got, ok := counts[name]; !ok || got != count {
+				t.Errorf("CountEmotions(%q) = %v, want %v", tt.userID, counts, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestPatchEmotion(t *testing.T) {
+	ctx := context.Background()
+	// Emotions stored before the catalog had metadata only have a name.
+	ds := NewDiaryService(memory.New(config.MemoryConfig{
+		Emotions: []string{"기쁨"},
+	}), time.UTC)
+	emoji, color := "😀", "#FFD700"
+	if _, err := ds.PatchEmotion(ctx, "기쁨", EmotionPatch{Emoji: &emoji, Color: &color}); err != nil {
+		t.Fatal(err)
+	}
+	valence := model.ValencePositive
+	if _, err := ds.PatchEmotion(ctx, "기쁨", EmotionPatch{Valence: &valence}); err != nil {
+		t.Fatal(err)
+	}
+	emotions, err := ds.Emotions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := model.Emotion{Name: "기쁨", Emoji: emoji, Color: color, Valence: valence}
+	if len(emotions) != 1 || emotions[0] != want {
+		t.Errorf("got %+v, want [%+v]", emotions, want)
+	}
+
+	invalid := "neutral"
+	if _, err := ds.PatchEmotion(ctx, "기쁨", EmotionPatch{Valence: &invalid}); !errors.Is(err, ErrInvalidValence) {
+		t.Errorf("patching an unknown valence: got %v, want ErrInvalidValence", err)
+	}
+	if _, err := ds.PatchEmotion(ctx, "슬픔", EmotionPatch{Emoji: &emoji}); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("patching a missing emotion: got %v, want ErrNotFound", err)
+	}
+	if _, err := ds.AddEmotion(ctx, model.Emotion{Name: "기쁨", Emoji: emoji, Color: color, Valence: valence}); !errors.Is(err, ErrEmotionExists) {
+		t.Errorf("adding an existing emotion: got %v, want ErrEmotionExists", err)
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"dailyscoop-backend/repository"
+)
+
+// Cursors are opaque to clients: the position of a diary, or the offset into
+// search results ranked by relevance, encoded as base64url JSON.
+type cursorToken struct {
+	Date   time.Time `json:"d,omitempty"`
+	ID     string    `json:"i,omitempty"`
+	Offset int       `json:"o,omitempty"`
+}
+
+func encodeCursor(cursor repository.DiaryCursor) string {
+	b, _ := json.Marshal(cursorToken{
+		Date: cursor.Date,
+		ID:   cursor.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (repository.DiaryCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return repository.DiaryCursor{}, ErrInvalidCursor
+	}
+	var token cursorToken
+	if err := json.Unmarshal(b, &token); err != nil || token.ID == "" {
+		return repository.DiaryCursor{}, ErrInvalidCursor
+	}
+	return repository.DiaryCursor{
+		Date: token.Date,
+		ID:   token.ID,
+	}, nil
+}
+
+func encodeOffsetCursor(offset int) string {
+	b, _ := json.Marshal(cursorToken{
+		Offset: offset,
+	})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeOffsetCursor(s string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	var token cursorToken
+	if err := json.Unmarshal(b, &token); err != nil || token.Offset <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return token.Offset, nil
+}
@@ -3,308 +3,470 @@ package service
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	uuid "github.com/satori/go.uuid"
 
-	"dailyscoop-backend/config"
 	"dailyscoop-backend/model"
+	"dailyscoop-backend/period"
+	"dailyscoop-backend/repository"
+	"dailyscoop-backend/search"
+)
+
+var (
+	ErrDiaryExists   = errors.New("service: diary already exists on the date")
+	ErrAmbiguousDate = errors.New("service: several diaries exist on the date")
+	ErrInvalidCursor = errors.New("service: invalid cursor")
 )
 
 type DiaryService struct {
-	cfg config.MongoConfig
-	mc  *mongo.Client
+	repo repository.Repository
+	loc  *time.Location
+
+	reportQueue   chan ReportRequest
+	reportMu      sync.Mutex
+	reportPending map[ReportRequest]bool
 }
 
-func NewDiaryService(cfg config.MongoConfig, mc *mongo.Client) *DiaryService {
+// NewDiaryService returns a DiaryService that computes days in loc for users
+// without a time zone of their own.
+func NewDiaryService(repo repository.Repository, loc *time.Location) *DiaryService {
 	return &DiaryService{
-		cfg: cfg,
-		mc:  mc,
+		repo:          repo,
+		loc:           loc,
+		reportQueue:   make(chan ReportRequest, reportQueueSize),
+		reportPending: make(map[ReportRequest]bool),
 	}
 }
 
-func (ds *DiaryService) DiariesByUserID(ctx context.Context, userID string, sort int) ([]model.Diary, error) {
-	coll := ds.mc.Database(ds.cfg.Database).Collection("diaries")
-	option := options.Find().SetSort(bson.M{
-		model.DiaryDateKey: sort,
-	})
-	cursor, err := coll.Find(ctx, bson.M{model.DiaryUserIDKey: userID}, option)
+// Location returns the time zone in which the days of the user begin and end.
+func (ds *DiaryService) Location(ctx context.Context, userID string) (*time.Location, error) {
+	user, err := ds.repo.Users.FindByID(ctx, userID)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ds.loc, nil
+		}
 		return nil, err
 	}
-	defer cursor.Close(ctx)
-	var diaries []model.Diary
-	for cursor.Next(ctx) {
-		var diary model.Diary
-		if err := cursor.Decode(&diary); err != nil {
-			return nil, err
-		}
-		diaries = append(diaries, diary)
+	if user.Timezone == "" {
+		return ds.loc, nil
 	}
-	return diaries, nil
+	loc, err := LoadLocation(user.Timezone)
+	if err != nil {
+		return ds.loc, nil
+	}
+	return loc, nil
 }
 
-func (ds *DiaryService) Calendar(ctx context.Context, userID string, typ string, date time.Time, sort int) ([]model.Diary, error) {
-	coll := ds.mc.Database(ds.cfg.Database).Collection("diaries")
-	option := options.Find().SetSort(bson.M{
-		model.DiaryDateKey: sort,
-	})
-	var diaries []model.Diary
-	if typ == "monthly" {
-		newDate := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
-		cursor, err := coll.Find(ctx, bson.M{
-			model.DiaryUserIDKey: userID,
-			model.DiaryDateKey: bson.M{
-				"$gte": newDate,
-				"$lt":  newDate.AddDate(0, 1, -1),
-			},
-		}, option)
-		if err != nil {
-			return nil, err
+// DiaryListOptions selects a page of diaries. Cursor is the NextCursor of the
+// previous page, and Fields limits the diary keys that are loaded. With a
+// Search query, a Sort of zero orders the diaries by relevance.
+type DiaryListOptions struct {
+	Search string
+	Filter DiaryFilter
+	Sort   int
+	Limit  int
+	Cursor string
+	Fields []string
+}
+
+// DiaryFilter narrows a diary listing down by the diary fields. Zero values
+// do not filter.
+type DiaryFilter struct {
+	// Emotions the diary has to have: all of them if AllEmotions is set,
+	// otherwise at least one.
+	Emotions    []string
+	AllEmotions bool
+	Theme       string
+	// From and To bound the date, To exclusive.
+	From      time.Time
+	To        time.Time
+	HasImage  *bool
+	MinLength int
+}
+
+// DiaryPage is a page of diaries. NextCursor is empty on the last page. When
+// searching, Matches holds the match of each diary at the same index.
+type DiaryPage struct {
+	Diaries    []model.Diary
+	Matches    []SearchMatch
+	NextCursor string
+}
+
+func (ds *DiaryService) ListDiaries(ctx context.Context, userID string, opts DiaryListOptions) (DiaryPage, error) {
+	query := repository.DiaryQuery{
+		UserID:      userID,
+		Emotions:    opts.Filter.Emotions,
+		AllEmotions: opts.Filter.AllEmotions,
+		Theme:       opts.Filter.Theme,
+		From:        opts.Filter.From,
+		To:          opts.Filter.To,
+		HasImage:    opts.Filter.HasImage,
+		MinLength:   opts.Filter.MinLength,
+		Sort:        opts.Sort,
+		Fields:      opts.Fields,
+	}
+	if opts.Search != "" {
+		query.Terms = search.QueryTerms(opts.Search)
+		if len(query.Terms) == 0 {
+			return DiaryPage{}, nil
 		}
-		defer cursor.Close(ctx)
-		for cursor.Next(ctx) {
-			var diary model.Diary
-			if err := cursor.Decode(&diary); err != nil {
-				return nil, err
-			}
-			diaries = append(diaries, diary)
+		// Snippets need the content whatever the caller asked for.
+		query.Fields = nil
+		if opts.Sort == 0 {
+			return ds.searchByRelevance(ctx, query, opts)
 		}
-	} else {
-		newDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-		startDay := newDate.AddDate(0, 0, -int(newDate.Weekday()))
-		cursor, err := coll.Find(ctx, bson.M{
-			model.DiaryUserIDKey: userID,
-			model.DiaryDateKey: bson.M{
-				"$gte": startDay,
-				"$lt":  startDay.AddDate(0, 0, 7),
-			},
-		}, option)
+	}
+	if opts.Cursor != "" {
+		cursor, err := decodeCursor(opts.Cursor)
 		if err != nil {
-			return nil, err
-		}
-		defer cursor.Close(ctx)
-		for cursor.Next(ctx) {
-			var diary model.Diary
-			if err := cursor.Decode(&diary); err != nil {
-				return nil, err
-			}
-			diaries = append(diaries, diary)
+			return DiaryPage{}, err
 		}
+		query.After = &cursor
 	}
-	return diaries, nil
-}
-
-func (ds *DiaryService) DiaryByUserIDAndDate(ctx context.Context, userID string, date time.Time) (model.Diary, error) {
-	coll := ds.mc.Database(ds.cfg.Database).Collection("diaries")
-	newDate := time.Date(
-		date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	var diary model.Diary
-	if err := coll.FindOne(ctx, bson.M{
-		model.DiaryUserIDKey: userID,
-		model.DiaryDateKey: bson.M{
-			"$gte": newDate,
-			"$lt":  newDate.AddDate(0, 0, 1),
-		},
-	}).Decode(&diary); err != nil {
-		return model.Diary{}, err
+	if opts.Limit > 0 {
+		// One extra diary tells whether there is a next page.
+		query.Limit = opts.Limit + 1
 	}
-	return diary, nil
+	diaries, err := ds.repo.Diaries.Find(ctx, query)
+	if err != nil {
+		return DiaryPage{}, err
+	}
+	page := DiaryPage{Diaries: diaries}
+	if opts.Limit > 0 && len(diaries) > opts.Limit {
+		page.Diaries = diaries[:opts.Limit]
+		last := page.Diaries[opts.Limit-1]
+		page.NextCursor = encodeCursor(repository.DiaryCursor{
+			Date: last.Date,
+			ID:   last.ID,
+		})
+	}
+	if opts.Search != "" {
+		page.Matches = matchDiaries(opts.Search, page.Diaries)
+	}
+	return page, nil
 }
 
-func (ds *DiaryService) WriteDiary(ctx context.Context, diary model.Diary) error {
-	coll := ds.mc.Database(ds.cfg.Database).Collection("diaries")
-	date := time.Date(diary.Date.Year(), diary.Date.Month(), diary.Date.Day(), 0, 0, 0, 0, diary.Date.Location())
-
-	if _, err := coll.UpdateOne(ctx, bson.M{
-		model.DiaryDateKey: bson.M{
-			"$gte": date,
-			"$lt":  date.AddDate(0, 0, 1),
-		},
-		model.DiaryUserIDKey: diary.UserID,
-	}, bson.M{
-		"$set": bson.M{
-			model.DiaryContentKey:  diary.Content,
-			model.DiaryImageKey:    diary.Image,
-			model.DiaryEmotionsKey: diary.Emotions,
-			model.DiaryThemeKey:    diary.Theme,
-		},
-		"$setOnInsert": bson.M{
-			model.DiaryDateKey: diary.Date,
-		},
-	}, options.Update().SetUpsert(true)); err != nil {
-		return err
-	}
-	return nil
+// Calendar returns the diaries written in the period.
+func (ds *DiaryService) Calendar(ctx context.Context, userID string, p period.Period, sort int) ([]model.Diary, error) {
+	return ds.repo.Diaries.FindInRange(ctx, userID, p.Start, p.End, sort)
 }
 
-func (ds *DiaryService) DeleteDiary(ctx context.Context, userID string, date time.Time) error {
-	coll := ds.mc.Database(ds.cfg.Database).Collection("diaries")
-	newDate := time.Date(
-		date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	_, err := coll.DeleteOne(ctx, bson.M{
-		model.DiaryUserIDKey: userID,
-		model.DiaryDateKey: bson.M{
-			"$gte": newDate,
-			"$lt":  newDate.AddDate(0, 0, 1),
-		},
-	})
+// DiariesByUserIDAndDate returns the diaries written on the day of date,
+// oldest first.
+func (ds *DiaryService) DiariesByUserIDAndDate(ctx context.Context, userID string, date time.Time) ([]model.Diary, error) {
+	loc, err := ds.Location(ctx, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	newDate := startOfDay(date, loc)
+	return ds.repo.Diaries.FindInRange(ctx, userID, newDate, newDate.AddDate(0, 0, 1), 1)
 }
 
-func (ds *DiaryService) ThemeExists(ctx context.Context, name string) (bool, error) {
-	coll := ds.mc.Database(ds.cfg.Database).Collection("themes")
-	if err := coll.FindOne(ctx, bson.M{
-		model.ThemeNameKey: name,
-	}).Err(); err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return false, nil
+func (ds *DiaryService) DiaryByID(ctx context.Context, userID string, id string) (model.Diary, error) {
+	return ds.repo.Diaries.FindByID(ctx, userID, id)
+}
+
+// WriteDiary adds a new diary when the user keeps multiple entries per day,
+// and otherwise overwrites the diary of the same day. It fails with
+// ErrRetiredEmotion for retired emotions the overwritten diary did not have.
+func (ds *DiaryService) WriteDiary(ctx context.Context, diary model.Diary) (model.Diary, error) {
+	multipleEntries, err := ds.multipleEntries(ctx, diary.UserID)
+	if err != nil {
+		return model.Diary{}, err
+	}
+	diary.ID = uuid.NewV4().String()
+	indexDiary(&diary)
+	if multipleEntries {
+		if err := ds.checkRetired(ctx, diary.Emotions, nil); err != nil {
+			return model.Diary{}, err
 		}
-		return false, err
+		if err := ds.repo.Diaries.Insert(ctx, diary); err != nil {
+			return model.Diary{}, err
+		}
+		if err := ds.recordRevision(ctx, diary); err != nil {
+			return model.Diary{}, err
+		}
+		if err := ds.diariesChanged(ctx, diary.UserID, diary.Date); err != nil {
+			return model.Diary{}, err
+		}
+		return diary, nil
+	}
+	loc, err := ds.Location(ctx, diary.UserID)
+	if err != nil {
+		return model.Diary{}, err
+	}
+	date := startOfDay(diary.Date, loc)
+	old, err := ds.repo.Diaries.FindOneInRange(ctx, diary.UserID, date, date.AddDate(0, 0, 1))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return model.Diary{}, err
+	}
+	if err := ds.checkRetired(ctx, diary.Emotions, old.Emotions); err != nil {
+		return model.Diary{}, err
+	}
+	if old.ID != "" {
+		if err := ds.ensureBaseRevision(ctx, old); err != nil {
+			return model.Diary{}, err
+		}
+	}
+	diary, err = ds.repo.Diaries.UpsertInRange(ctx, diary, date, date.AddDate(0, 0, 1))
+	if err != nil {
+		return model.Diary{}, err
+	}
+	if err := ds.recordRevision(ctx, diary); err != nil {
+		return model.Diary{}, err
+	}
+	if err := ds.diariesChanged(ctx, diary.UserID, diary.Date); err != nil {
+		return model.Diary{}, err
 	}
-	return true, nil
+	return diary, nil
 }
 
-func (ds *DiaryService) EmotionExists(ctx context.Context, name string) (bool, error) {
-	coll := ds.mc.Database(ds.cfg.Database).Collection("emotions")
-	if err := coll.FindOne(ctx, bson.M{
-		model.EmotionNameKey: name,
-	}).Err(); err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
+func (ds *DiaryService) MultipleEntries(ctx context.Context, userID string) (bool, error) {
+	return ds.multipleEntries(ctx, userID)
+}
+
+func (ds *DiaryService) multipleEntries(ctx context.Context, userID string) (bool, error) {
+	user, err := ds.repo.Users.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return false, nil
 		}
 		return false, err
 	}
-	return true, nil
+	return user.MultipleEntries, nil
 }
 
-func (ds *DiaryService) FindDiaries(ctx context.Context, userID string, content string, sort int) ([]model.Diary, error) {
-	coll := ds.mc.Database(ds.cfg.Database).Collection("diaries")
-	option := options.Find().SetSort(bson.M{
-		model.DiaryDateKey: sort,
-	})
-	cursor, err := coll.Find(ctx, bson.M{
-		model.DiaryUserIDKey: userID,
-		model.DiaryContentKey: bson.M{
-			"$regex": content,
-		},
-	}, option)
+// UpdateDiary replaces the diary with the same ID. Unless the user keeps
+// multiple entries per day, moving a diary to a date that already has one
+// fails with ErrDiaryExists. Retired emotions the diary already has may be
+// kept, but not added.
+func (ds *DiaryService) UpdateDiary(ctx context.Context, diary model.Diary) (model.Diary, error) {
+	old, err := ds.repo.Diaries.FindByID(ctx, diary.UserID, diary.ID)
 	if err != nil {
-		return nil, err
+		return model.Diary{}, err
+	}
+	if diary.Date.IsZero() {
+		diary.Date = old.Date
+	}
+	if err := ds.checkRetired(ctx, diary.Emotions, old.Emotions); err != nil {
+		return model.Diary{}, err
 	}
-	var diaries []model.Diary
-	for cursor.Next(ctx) {
-		var diary model.Diary
-		if err := cursor.Decode(&diary); err != nil {
-			return nil, err
+	indexDiary(&diary)
+	multipleEntries, err := ds.multipleEntries(ctx, diary.UserID)
+	if err != nil {
+		return model.Diary{}, err
+	}
+	loc, err := ds.Location(ctx, diary.UserID)
+	if err != nil {
+		return model.Diary{}, err
+	}
+	if !multipleEntries && !sameDay(old.Date, diary.Date, loc) {
+		date := startOfDay(diary.Date, loc)
+		_, err := ds.repo.Diaries.FindOneInRange(ctx, diary.UserID, date, date.AddDate(0, 0, 1))
+		if err == nil {
+			return model.Diary{}, ErrDiaryExists
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return model.Diary{}, err
 		}
-		diaries = append(diaries, diary)
 	}
-	return diaries, nil
+	if err := ds.ensureBaseRevision(ctx, old); err != nil {
+		return model.Diary{}, err
+	}
+	if err := ds.repo.Diaries.Update(ctx, diary); err != nil {
+		return model.Diary{}, err
+	}
+	if err := ds.recordRevision(ctx, diary); err != nil {
+		return model.Diary{}, err
+	}
+	if err := ds.diariesChanged(ctx, diary.UserID, old.Date, diary.Date); err != nil {
+		return model.Diary{}, err
+	}
+	return diary, nil
 }
 
-func (ds *DiaryService) CountDiaries(ctx context.Context, typ string, date time.Time, userID string) (int64, int, error) {
-	coll := ds.mc.Database(ds.cfg.Database).Collection("diaries")
-	if typ == "weekly" {
-		newDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-		startDate := newDate.AddDate(0, 0, -int(newDate.Weekday()))
-		count, err := coll.CountDocuments(ctx, bson.M{
-			model.DiaryUserIDKey: userID,
-			model.DiaryDateKey: bson.M{
-				"$gte": startDate,
-				"$lt":  startDate.AddDate(0, 0, 7),
-			},
-		})
+// DiaryPatch holds the fields of a partial diary update. Nil fields are left
+// unchanged.
+type DiaryPatch struct {
+	Content *string
+	Image   *string
+	// Date is the day to move the diary to. The diary keeps its time of day
+	// unless Clock is set.
+	Date *time.Time
+	// Clock is the time of day since midnight, and sets the time of the
+	// diary on its current day when Date is nil.
+	Clock    *time.Duration
+	Emotions []string
+	Theme    *string
+}
+
+func (ds *DiaryService) PatchDiary(ctx context.Context, userID string, id string, patch DiaryPatch) (model.Diary, error) {
+	diary, err := ds.repo.Diaries.FindByID(ctx, userID, id)
+	if err != nil {
+		return model.Diary{}, err
+	}
+	if patch.Content != nil {
+		diary.Content = *patch.Content
+	}
+	if patch.Image != nil {
+		diary.Image = *patch.Image
+	}
+	if patch.Date != nil || patch.Clock != nil {
+		loc, err := ds.Location(ctx, userID)
 		if err != nil {
-			return -1, -1, err
+			return model.Diary{}, err
 		}
-		return count, 7, nil
-	} else if typ == "monthly" {
-		startDate := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
-		endDate := startDate.AddDate(0, 1, -1)
-		count, err := coll.CountDocuments(ctx, bson.M{
-			model.DiaryUserIDKey: userID,
-			model.DiaryDateKey: bson.M{
-				"$gte": startDate,
-				"$lt":  endDate,
-			},
-		})
-		if err != nil {
-			return -1, -1, err
+		day := startOfDay(diary.Date, loc)
+		if patch.Date != nil {
+			day = startOfDay(*patch.Date, loc)
 		}
-		return count, endDate.Day(), nil
-	}
-	startDate := time.Date(date.Year(), 1, 1, 0, 0, 0, 0, date.Location())
-	count, err := coll.CountDocuments(ctx, bson.M{
-		model.DiaryUserIDKey: userID,
-		model.DiaryDateKey: bson.M{
-			"$gte": startDate,
-			"$lt":  startDate.AddDate(1, 0, -1),
-		},
-	})
+		clock := clockOf(diary.Date, loc)
+		if patch.Clock != nil {
+			clock = *patch.Clock
+		}
+		diary.Date = atClock(day, clock)
+	}
+	if patch.Emotions != nil {
+		diary.Emotions = patch.Emotions
+	}
+	if patch.Theme != nil {
+		diary.Theme = *patch.Theme
+	}
+	return ds.UpdateDiary(ctx, diary)
+}
+
+// PatchDiaryByDate applies a partial update to the diary of the day of date.
+// It fails with ErrAmbiguousDate when the day has several diaries.
+func (ds *DiaryService) PatchDiaryByDate(ctx context.Context, userID string, date time.Time, patch DiaryPatch) (model.Diary, error) {
+	diaries, err := ds.DiariesByUserIDAndDate(ctx, userID, date)
 	if err != nil {
-		return -1, -1, err
+		return model.Diary{}, err
 	}
-	return count, time.Date(date.Year(), 12, 31, 0, 0, 0, 0, date.Location()).YearDay(), nil
+	if len(diaries) == 0 {
+		return model.Diary{}, repository.ErrNotFound
+	}
+	if len(diaries) > 1 {
+		return model.Diary{}, ErrAmbiguousDate
+	}
+	return ds.PatchDiary(ctx, userID, diaries[0].ID, patch)
 }
 
-func (ds *DiaryService) CountEmotions(ctx context.Context, userID string, typ string, date time.Time) (map[string]int, error) {
-	emotionColl := ds.mc.Database(ds.cfg.Database).Collection("emotions")
-	cursor, err := emotionColl.Find(ctx, bson.M{})
+// DeleteDiary moves the diaries written on the day of date to the trash.
+func (ds *DiaryService) DeleteDiary(ctx context.Context, userID string, date time.Time) error {
+	loc, err := ds.Location(ctx, userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	emotions := make(map[string]int)
-	for cursor.Next(ctx) {
-		var emotion model.Emotion
-		if err := cursor.Decode(&emotion); err != nil {
-			return nil, err
-		}
-		emotions[emotion.Name] = 0
-	}
-	coll := ds.mc.Database(ds.cfg.Database).Collection("diaries")
-	var diaryCursor *mongo.Cursor
-	if typ == "monthly" {
-		var err error
-		newDate := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
-		diaryCursor, err = coll.Find(ctx, bson.M{
-			model.DiaryUserIDKey: userID,
-			model.DiaryDateKey: bson.M{
-				"$gte": newDate,
-				"$lt":  newDate.AddDate(0, 1, -1),
-			},
-		})
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		var err error
-		newDate := time.Date(date.Year(), 1, 1, 0, 0, 0, 0, date.Location())
-		diaryCursor, err = coll.Find(ctx, bson.M{
-			model.DiaryUserIDKey: userID,
-			model.DiaryDateKey: bson.M{
-				"$gte": newDate,
-				"$lt":  newDate.AddDate(1, 0, -1),
-			},
-		})
-		if err != nil {
-			return nil, err
+	newDate := startOfDay(date, loc)
+	diaries, err := ds.repo.Diaries.FindInRange(ctx, userID, newDate, newDate.AddDate(0, 0, 1), 1)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, diary := range diaries {
+		if err := ds.repo.Diaries.Trash(ctx, userID, diary.ID, now); err != nil {
+			return err
 		}
 	}
+	if len(diaries) == 0 {
+		return nil
+	}
+	return ds.diariesChanged(ctx, userID, newDate)
+}
 
-	for diaryCursor.Next(ctx) {
-		var diary model.Diary
-		if err := diaryCursor.Decode(&diary); err != nil {
-			return nil, err
-		}
-		for _, emotion := range diary.Emotions {
-			emotions[emotion] = emotions[emotion] + 1
+// DeleteDiaryByID moves a diary to the trash.
+func (ds *DiaryService) DeleteDiaryByID(ctx context.Context, userID string, id string) error {
+	diary, err := ds.repo.Diaries.FindByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := ds.repo.Diaries.Trash(ctx, userID, id, time.Now()); err != nil {
+		return err
+	}
+	return ds.diariesChanged(ctx, userID, diary.Date)
+}
+
+func (ds *DiaryService) ThemeExists(ctx context.Context, name string) (bool, error) {
+	return ds.repo.Themes.Exists(ctx, name)
+}
+
+// EmotionExists reports whether name is an emotion of the catalog or a custom
+// emotion of the user.
+func (ds *DiaryService) EmotionExists(ctx context.Context, userID string, name string) (bool, error) {
+	exists, err := ds.repo.Emotions.Exists(ctx, name)
+	if err != nil || exists {
+		return exists, err
+	}
+	return ds.repo.CustomEmotions.Exists(ctx, userID, name)
+}
+
+// DiaryCount is the number of diaries written in a period, the number of
+// days with at least one diary in it and the length of the period in days.
+type DiaryCount struct {
+	Diaries     int64
+	DaysWritten int64
+	Days        int
+}
+
+func (ds *DiaryService) CountDiaries(ctx context.Context, userID string, p period.Period) (DiaryCount, error) {
+	loc, err := ds.Location(ctx, userID)
+	if err != nil {
+		return DiaryCount{}, err
+	}
+	count, err := ds.repo.Stats.CountDiaries(ctx, userID, p.Start, p.End, loc)
+	if err != nil {
+		return DiaryCount{}, err
+	}
+	return DiaryCount{
+		Diaries:     count.Entries,
+		DaysWritten: count.Days,
+		Days:        p.Days(),
+	}, nil
+}
+
+// CountEmotions counts the diaries with each emotion in the period. Every
+// entry counts, so a day with several diaries contributes the emotions of
+// each of them. Emotions of the catalog that were not felt count zero unless
+// they are retired, and so do the custom emotions of the user.
+func (ds *DiaryService) CountEmotions(ctx context.Context, userID string, p period.Period) (map[string]int, error) {
+	emotions, err := ds.repo.Stats.CountEmotions(ctx, userID, p.Start, p.End)
+	if err != nil {
+		return nil, err
+	}
+	all, err := ds.activeEmotions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, emotion := range all {
+		if _, ok := emotions[emotion.Name]; !ok {
+			emotions[emotion.Name] = 0
 		}
 	}
 	return emotions, nil
 }
+
+// startOfDay returns the midnight in loc that starts the day of t.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// clockOf returns the time of day of t in loc as the time since midnight on
+// a clock that ignores daylight saving changes.
+func clockOf(t time.Time, loc *time.Location) time.Duration {
+	t = t.In(loc)
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// atClock returns the time on day that is clock past its midnight.
+func atClock(day time.Time, clock time.Duration) time.Time {
+	clock = clock.Truncate(time.Second)
+	h, m, sec := int(clock/time.Hour), int(clock%time.Hour/time.Minute), int(clock%time.Minute/time.Second)
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, sec, 0, day.Location())
+}
+
+func sameDay(a, b time.Time, loc *time.Location) bool {
+	return startOfDay(a, loc).Equal(startOfDay(b, loc))
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+var (
+	// ErrIdentityInUse means the identity signs in to another user, whose
+	// account has to be merged instead.
+	ErrIdentityInUse = errors.New("service: identity is linked to another user")
+	// ErrProviderLinked means the user already has an identity at the
+	// provider.
+	ErrProviderLinked = errors.New("service: provider already linked")
+	// ErrLastSignIn means unlinking would leave the user without a way to
+	// sign in.
+	ErrLastSignIn = errors.New("service: last way to sign in")
+)
+
+// UserByIdentity returns the user the identity signs in to. Users who signed
+// up through a provider before identities were linked are found by legacyID,
+// the ID their account was made with, and get the identity linked.
+func (us *UserService) UserByIdentity(ctx context.Context, identity model.Identity, legacyID string) (model.User, error) {
+	user, err := us.repo.Users.FindByIdentity(ctx, identity.Provider, identity.Subject)
+	if err == nil || !errors.Is(err, repository.ErrNotFound) {
+		return user, err
+	}
+	user, err = us.legacyUser(ctx, identity.Provider, legacyID)
+	if err != nil {
+		return model.User{}, err
+	}
+	identity.LinkedAt = time.Now()
+	if err := us.repo.Users.AddIdentity(ctx, user.ID, identity); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			// A concurrent login linked it first.
+			return us.repo.Users.FindByIdentity(ctx, identity.Provider, identity.Subject)
+		}
+		return model.User{}, err
+	}
+	user.Identities = append(user.Identities, identity)
+	return user, nil
+}
+
+// legacyUser returns the user made with legacyID by a login through provider
+// before identities were linked. Users with a password signed up themselves,
+// so an ID that only looks like the one of a provider is not theirs.
+func (us *UserService) legacyUser(ctx context.Context, provider string, legacyID string) (model.User, error) {
+	if legacyID == "" {
+		return model.User{}, repository.ErrNotFound
+	}
+	user, err := us.repo.Users.FindByID(ctx, legacyID)
+	if err != nil {
+		return model.User{}, err
+	}
+	if _, linked := user.Identity(provider); linked || user.Password != "" {
+		return model.User{}, repository.ErrNotFound
+	}
+	return user, nil
+}
+
+// RegisterWithIdentity registers a user who signs in with the identity for
+// the first time under a new ID. When a concurrent login registered the
+// identity first, it returns that user.
+func (us *UserService) RegisterWithIdentity(ctx context.Context, user model.User, identity model.Identity) (model.User, error) {
+	identity.LinkedAt = time.Now()
+	user.ID = uuid.NewV4().String()
+	user.CreatedAt = identity.LinkedAt
+	user.Password = ""
+	user.Identities = []model.Identity{identity}
+	if err := us.repo.Users.Insert(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return us.repo.Users.FindByIdentity(ctx, identity.Provider, identity.Subject)
+		}
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+// LinkIdentity lets the user sign in with the identity too. legacyID is the
+// ID a login with the identity made accounts with before identities were
+// linked.
+func (us *UserService) LinkIdentity(ctx context.Context, userID string, identity model.Identity, legacyID string) error {
+	owner, err := us.repo.Users.FindByIdentity(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		if owner.ID == userID {
+			return nil
+		}
+		return ErrIdentityInUse
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if legacy, err := us.legacyUser(ctx, identity.Provider, legacyID); err == nil && legacy.ID != userID {
+		return ErrIdentityInUse
+	} else if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	user, err := us.repo.Users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if _, linked := user.Identity(identity.Provider); linked {
+		return ErrProviderLinked
+	}
+	identity.LinkedAt = time.Now()
+	if err := us.repo.Users.AddIdentity(ctx, userID, identity); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			// Linked to another user since it was looked up.
+			return ErrIdentityInUse
+		}
+		return err
+	}
+	return nil
+}
+
+// UnlinkIdentity stops the identity of the user at provider from signing in
+// to them. The last way a user has to sign in cannot be unlinked.
+func (us *UserService) UnlinkIdentity(ctx context.Context, userID string, provider string) error {
+	user, err := us.repo.Users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if _, linked := user.Identity(provider); !linked {
+		return repository.ErrNotFound
+	}
+	if user.Password == "" && len(user.Identities) == 1 {
+		return ErrLastSignIn
+	}
+	return us.repo.Users.RemoveIdentity(ctx, userID, provider)
+}
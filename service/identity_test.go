@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"dailyscoop-backend/config"
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/period"
+	"dailyscoop-backend/repository"
+	"dailyscoop-backend/repository/memory"
+)
+
+func TestUserByIdentityLinksLegacyUsers(t *testing.T) {
+	ctx := context.Background()
+	us := NewUserService(memory.New(config.MemoryConfig{}), time.Hour)
+	// Kakao users used to be stored under their Kakao ID, and password users
+	// pick their own.
+	if err := us.RegisterUser(ctx, model.User{ID: "1234", Nickname: "kakao"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := us.RegisterUser(ctx, model.User{ID: "5678", Password: "secret", Nickname: "password"}); err != nil {
+		t.Fatal(err)
+	}
+	kakao := model.Identity{Provider: "kakao", Subject: "1234"}
+
+	user, err := us.UserByIdentity(ctx, kakao, "1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != "1234" {
+		t.Errorf("got user %q, want 1234", user.ID)
+	}
+	if user, err = us.UserByIdentity(ctx, kakao, ""); err != nil {
+		t.Fatalf("identity was not linked: %v", err)
+	} else if user.ID != "1234" {
+		t.Errorf("got user %q, want 1234", user.ID)
+	}
+	if _, err := us.UserByIdentity(ctx, model.Identity{Provider: "kakao", Subject: "5678"}, "5678"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("password user: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestLinkIdentity(t *testing.T) {
+	ctx := context.Background()
+	us := NewUserService(memory.New(config.MemoryConfig{}), time.Hour)
+	google := model.Identity{Provider: "google", Subject: "g1", Email: "user@example.com"}
+	first, err := us.RegisterWithIdentity(ctx, model.User{Nickname: "first"}, google)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := us.RegisterWithIdentity(ctx, model.User{Nickname: "second"}, model.Identity{Provider: "kakao", Subject: "k1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := us.LinkIdentity(ctx, second.ID, google, "user@example.com"); !errors.Is(err, ErrIdentityInUse) {
+		t.Errorf("linking an identity of another user: got %v, want ErrIdentityInUse", err)
+	}
+	if err := us.LinkIdentity(ctx, first.ID, model.Identity{Provider: "google", Subject: "g2"}, ""); !errors.Is(err, ErrProviderLinked) {
+		t.Errorf("linking a second google identity: got %v, want ErrProviderLinked", err)
+	}
+	apple := model.Identity{Provider: "apple", Subject: "a1"}
+	if err := us.LinkIdentity(ctx, first.ID, apple, ""); err != nil {
+		t.Fatal(err)
+	}
+	if user, err := us.UserByIdentity(ctx, apple, ""); err != nil || user.ID != first.ID {
+		t.Errorf("UserByIdentity(apple) = %q, %v; want %q", user.ID, err, first.ID)
+	}
+
+	if err := us.UnlinkIdentity(ctx, first.ID, "google"); err != nil {
+		t.Fatal(err)
+	}
+	if err := us.UnlinkIdentity(ctx, first.ID, "apple"); !errors.Is(err, ErrLastSignIn) {
+		t.Errorf("unlinking the last identity: got %v, want ErrLastSignIn", err)
+	}
+	if err := us.UnlinkIdentity(ctx, first.ID, "google"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("unlinking an unlinked provider: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestMergeUsers(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New(config.MemoryConfig{Emotions: []string{"기쁨"}})
+	us := NewUserService(repo, time.Hour)
+	ds := NewDiaryService(repo, time.UTC)
+	into, err := us.RegisterWithIdentity(ctx, model.User{Nickname: "into"}, model.Identity{Provider: "google", Subject: "g1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, err := us.RegisterWithIdentity(ctx, model.User{Nickname: "from"}, model.Identity{Provider: "kakao", Subject: "k1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(userID string, day int) {
+		t.Helper()
+		if _, err := ds.WriteDiary(ctx, model.Diary{
+			UserID:   userID,
+			Content:  "content",
+			Image:    "image",
+			Date:     time.Date(2026, 3, day, 0, 0, 0, 0, time.UTC),
+			Emotions: []string{"기쁨"},
+			Theme:    "basic",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(into.ID, 1)
+	write(from.ID, 1)
+	write(from.ID, 2)
+	for _, userID := range []string{into.ID, from.ID} {
+		if _, err := ds.AddCustomEmotion(ctx, model.CustomEmotion{UserID: userID, Name: "설렘", Emoji: "🥰", Valence: model.ValencePositive}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ds.MergeUsers(ctx, into.ID, into.ID); !errors.Is(err, ErrMergeSelf) {
+		t.Errorf("merging a user into themselves: got %v, want ErrMergeSelf", err)
+	}
+	if err := ds.MergeUsers(ctx, into.ID, from.ID); !errors.Is(err, ErrMergeConflict) {
+		t.Errorf("merging diaries of the same day: got %v, want ErrMergeConflict", err)
+	}
+	if err := us.UpdateMultipleEntries(ctx, into.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.MergeUsers(ctx, into.ID, from.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := ds.CountDiaries(ctx, into.ID, period.Period{
+		Start: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count.Diaries != 3 {
+		t.Errorf("got %d diaries after merging, want 3", count.Diaries)
+	}
+	emotions, err := ds.CustomEmotions(ctx, into.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emotions) != 1 {
+		t.Errorf("got %d custom emotions after merging, want 1", len(emotions))
+	}
+	if user, err := us.UserByIdentity(ctx, model.Identity{Provider: "kakao", Subject: "k1"}, ""); err != nil || user.ID != into.ID {
+		t.Errorf("kakao identity signs in to %q, %v; want %q", user.ID, err, into.ID)
+	}
+	if _, err := us.UserByID(ctx, from.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("merged user: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestMergeUsersWithTrashedDiaries(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New(config.MemoryConfig{Emotions: []string{"기쁨"}})
+	us := NewUserService(repo, time.Hour)
+	ds := NewDiaryService(repo, time.UTC)
+	into, err := us.RegisterWithIdentity(ctx, model.User{Nickname: "into"}, model.Identity{Provider: "google", Subject: "g1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, err := us.RegisterWithIdentity(ctx, model.User{Nickname: "from"}, model.Identity{Provider: "kakao", Subject: "k1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trashed model.Diary
+	for _, userID := range []string{into.ID, from.ID} {
+		trashed, err = ds.WriteDiary(ctx, model.Diary{
+			UserID:   userID,
+			Content:  "content",
+			Image:    "image",
+			Date:     time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			Emotions: []string{"기쁨"},
+			Theme:    "basic",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ds.DeleteDiaryByID(ctx, from.ID, trashed.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The trashed diary does not keep the users from merging, but it cannot
+	// be restored onto the day that has a diary.
+	if err := ds.MergeUsers(ctx, into.ID, from.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.RestoreDiary(ctx, into.ID, trashed.ID); !errors.Is(err, ErrDiaryExists) {
+		t.Errorf("restoring onto a day with a diary: got %v, want ErrDiaryExists", err)
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+// Memory is the diaries of a day in the past.
+type Memory struct {
+	Date    time.Time
+	Diaries []model.Diary
+}
+
+// Memories are the diaries written on the same month and day in previous
+// years, most recent first, and the diaries of one month and one week
+// before. Years without diaries are left out.
+type Memories struct {
+	YearsAgo []Memory
+	MonthAgo Memory
+	WeekAgo  Memory
+}
+
+// Memories returns the memories of the day of date in the time zone of the
+// user. A leap day has memories only in leap years, and a month ago from the
+// 31st is the last day of the previous month when it is shorter.
+func (ds *DiaryService) Memories(ctx context.Context, userID string, date time.Time) (Memories, error) {
+	loc, err := ds.Location(ctx, userID)
+	if err != nil {
+		return Memories{}, err
+	}
+	day := startOfDay(date, loc)
+	var memories Memories
+	first, err := ds.firstDiary(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return Memories{}, err
+	}
+	if err == nil {
+		firstYear := first.Date.In(loc).Year()
+		for year := day.Year() - 1; year >= firstYear; year-- {
+			past := time.Date(year, day.Month(), day.Day(), 0, 0, 0, 0, loc)
+			if past.Month() != day.Month() {
+				continue
+			}
+			memory, err := ds.memory(ctx, userID, past)
+			if err != nil {
+				return Memories{}, err
+			}
+			if len(memory.Diaries) > 0 {
+				memories.YearsAgo = append(memories.YearsAgo, memory)
+			}
+		}
+	}
+	memories.MonthAgo, err = ds.memory(ctx, userID, monthBefore(day))
+	if err != nil {
+		return Memories{}, err
+	}
+	memories.WeekAgo, err = ds.memory(ctx, userID, day.AddDate(0, 0, -7))
+	if err != nil {
+		return Memories{}, err
+	}
+	return memories, nil
+}
+
+func (ds *DiaryService) memory(ctx context.Context, userID string, day time.Time) (Memory, error) {
+	diaries, err := ds.repo.Diaries.FindInRange(ctx, userID, day, day.AddDate(0, 0, 1), 1)
+	if err != nil {
+		return Memory{}, err
+	}
+	return Memory{Date: day, Diaries: diaries}, nil
+}
+
+// firstDiary returns the oldest diary of the user with only its ID and date.
+func (ds *DiaryService) firstDiary(ctx context.Context, userID string) (model.Diary, error) {
+	diaries, err := ds.repo.Diaries.Find(ctx, repository.DiaryQuery{
+		UserID: userID,
+		Sort:   1,
+		Limit:  1,
+		Fields: []string{model.DiaryDateKey},
+	})
+	if err != nil {
+		return model.Diary{}, err
+	}
+	if len(diaries) == 0 {
+		return model.Diary{}, repository.ErrNotFound
+	}
+	return diaries[0], nil
+}
+
+// monthBefore returns the same day of the previous month, or its last day
+// when it has fewer days.
+func monthBefore(day time.Time) time.Time {
+	firstOfMonth := time.Date(day.Year(), day.Month()-1, 1, 0, 0, 0, 0, day.Location())
+	last := firstOfMonth.AddDate(0, 1, -1).Day()
+	d := day.Day()
+	if d > last {
+		d = last
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), d, 0, 0, 0, 0, day.Location())
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonthBefore(t *testing.T) {
+	tests := []struct {
+		day  time.Time
+		want time.Time
+	}{
+		{time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), time.Date(2026, 9, 16, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := monthBefore(tt.day); !got.Equal(tt.want) {
+			t.Errorf("monthBefore(%s) = %s, want %s", tt.day.Format("2006-01-02"), got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+var (
+	ErrMergeSelf = errors.New("service: user merged into themselves")
+	// ErrMergeConflict means both users have a diary on a day, while the
+	// user they are merged into keeps one diary per day.
+	ErrMergeConflict = errors.New("service: both users have diaries on the same day")
+)
+
+// MergeUsers moves the diaries, revisions, favorites, custom emotions and
+// identities of the user fromID to the user intoID, and deletes fromID. The
+// favorites and custom emotions intoID already has are kept as they are.
+//
+// The steps are not run in a transaction, but each can be repeated, so a
+// merge that failed part way is finished by merging the users again. fromID
+// is deleted last, and its identities sign in to one of the users throughout.
+func (ds *DiaryService) MergeUsers(ctx context.Context, intoID string, fromID string) error {
+	if intoID == fromID {
+		return ErrMergeSelf
+	}
+	into, err := ds.repo.Users.FindByID(ctx, intoID)
+	if err != nil {
+		return err
+	}
+	from, err := ds.repo.Users.FindByID(ctx, fromID)
+	if err != nil {
+		return err
+	}
+	for _, identity := range from.Identities {
+		if _, linked := into.Identity(identity.Provider); linked {
+			return ErrProviderLinked
+		}
+	}
+	if !into.MultipleEntries {
+		if err := ds.checkMergedDays(ctx, intoID, fromID); err != nil {
+			return err
+		}
+	}
+
+	if err := ds.dropDuplicateFavorites(ctx, intoID, fromID); err != nil {
+		return err
+	}
+	if err := ds.dropDuplicateCustomEmotions(ctx, intoID, fromID); err != nil {
+		return err
+	}
+	if err := ds.repo.Diaries.Reassign(ctx, fromID, intoID); err != nil {
+		return err
+	}
+	if err := ds.repo.Revisions.Reassign(ctx, fromID, intoID); err != nil {
+		return err
+	}
+	if err := ds.repo.Favorites.Reassign(ctx, fromID, intoID); err != nil {
+		return err
+	}
+	if err := ds.repo.CustomEmotions.Reassign(ctx, fromID, intoID); err != nil {
+		return err
+	}
+	if err := ds.repo.Reports.DeleteByUserID(ctx, fromID); err != nil {
+		return err
+	}
+	if err := ds.repo.Reports.MarkAllChanged(ctx, intoID); err != nil {
+		return err
+	}
+	if err := ds.repo.Users.MoveIdentities(ctx, fromID, intoID); err != nil {
+		return err
+	}
+	if err := ds.repo.Sessions.DeleteByUserID(ctx, fromID); err != nil {
+		return err
+	}
+	return ds.repo.Users.Delete(ctx, fromID)
+}
+
+// checkMergedDays fails with ErrMergeConflict when the diaries of both users
+// together have more than one diary on a day of intoID. Diaries in the trash
+// are moved too but left out of the check: RestoreDiary refuses to restore
+// one onto a day that has a diary.
+func (ds *DiaryService) checkMergedDays(ctx context.Context, intoID string, fromID string) error {
+	loc, err := ds.Location(ctx, intoID)
+	if err != nil {
+		return err
+	}
+	days := make(map[int64]bool)
+	for _, userID := range []string{intoID, fromID} {
+		diaries, err := ds.repo.Diaries.Find(ctx, repository.DiaryQuery{
+			UserID: userID,
+			Fields: []string{model.DiaryDateKey},
+		})
+		if err != nil {
+			return err
+		}
+		for _, diary := range diaries {
+			day := startOfDay(diary.Date, loc).Unix()
+			if days[day] {
+				return ErrMergeConflict
+			}
+			days[day] = true
+		}
+	}
+	return nil
+}
+
+func (ds *DiaryService) dropDuplicateFavorites(ctx context.Context, intoID string, fromID string) error {
+	favorites, err := ds.repo.Favorites.FindByUserID(ctx, fromID)
+	if err != nil {
+		return err
+	}
+	for _, favorite := range favorites {
+		exists, err := ds.repo.Favorites.Exists(ctx, intoID, favorite.Quote)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if err := ds.repo.Favorites.Delete(ctx, fromID, favorite.Quote); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (ds *DiaryService) dropDuplicateCustomEmotions(ctx context.Context, intoID string, fromID string) error {
+	emotions, err := ds.repo.CustomEmotions.FindByUserID(ctx, fromID)
+	if err != nil {
+		return err
+	}
+	for _, emotion := range emotions {
+		exists, err := ds.repo.CustomEmotions.Exists(ctx, intoID, emotion.Name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if err := ds.repo.CustomEmotions.Delete(ctx, fromID, emotion.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
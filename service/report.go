@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/period"
+	"dailyscoop-backend/repository"
+)
+
+const (
+	// reportQueueSize bounds the reports waiting to be generated. Requests
+	// beyond it are dropped, and the report is requested again the next time
+	// it is opened.
+	reportQueueSize = 256
+	// reportTopCount is the number of top emotions and themes in a report.
+	reportTopCount = 5
+)
+
+// ReportRequest asks for the yearly report of a user to be generated.
+type ReportRequest struct {
+	UserID string
+	Year   int
+}
+
+// ReportRequests returns the reports waiting to be generated by
+// GenerateYearlyReport.
+func (ds *DiaryService) ReportRequests() <-chan ReportRequest {
+	return ds.reportQueue
+}
+
+// YearlyReport returns the stored report of the year. When it is missing or
+// out of date, it is requested for generation in the background, and the
+// report is returned as it is: check Generated and Fresh.
+func (ds *DiaryService) YearlyReport(ctx context.Context, userID string, year int) (model.YearlyReport, error) {
+	report, err := ds.repo.Reports.FindYearly(ctx, userID, year)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			return model.YearlyReport{}, err
+		}
+		report = model.YearlyReport{UserID: userID, Year: year}
+	}
+	if !report.Fresh() {
+		ds.requestReport(ReportRequest{UserID: userID, Year: year})
+	}
+	return report, nil
+}
+
+// GenerateYearlyReport computes the report of a year and stores it. Changes
+// to the diaries while it runs leave the report out of date, and request it
+// again.
+func (ds *DiaryService) GenerateYearlyReport(ctx context.Context, req ReportRequest) error {
+	ds.reportMu.Lock()
+	delete(ds.reportPending, req)
+	ds.reportMu.Unlock()
+	report, err := ds.repo.Reports.FindYearly(ctx, req.UserID, req.Year)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		report = model.YearlyReport{UserID: req.UserID, Year: req.Year}
+	}
+	stats, err := ds.yearlyStats(ctx, req.UserID, req.Year)
+	if err != nil {
+		return err
+	}
+	report.GeneratedChanges = report.Changes
+	report.GeneratedAt = time.Now()
+	report.Stats = stats
+	return ds.repo.Reports.SaveYearly(ctx, report)
+}
+
+func (ds *DiaryService) yearlyStats(ctx context.Context, userID string, year int) (model.YearlyStats, error) {
+	loc, err := ds.Location(ctx, userID)
+	if err != nil {
+		return model.YearlyStats{}, err
+	}
+	p, err := period.Of(period.Yearly, time.Date(year, 1, 1, 0, 0, 0, 0, loc), time.Sunday)
+	if err != nil {
+		return model.YearlyStats{}, err
+	}
+	days, err := ds.repo.Stats.CountByDay(ctx, userID, p.Start, p.End, loc)
+	if err != nil {
+		return model.YearlyStats{}, err
+	}
+	emotions, err := ds.repo.Stats.CountEmotions(ctx, userID, p.Start, p.End)
+	if err != nil {
+		return model.YearlyStats{}, err
+	}
+	themes, err := ds.repo.Stats.CountThemes(ctx, userID, p.Start, p.End)
+	if err != nil {
+		return model.YearlyStats{}, err
+	}
+	diaries, err := ds.repo.Diaries.FindInRange(ctx, userID, p.Start, p.End, 1)
+	if err != nil {
+		return model.YearlyStats{}, err
+	}
+	stats := model.YearlyStats{
+		Days:        len(days),
+		TopEmotions: topCounts(emotions, reportTopCount),
+		TopThemes:   topCounts(themes, reportTopCount),
+		Highlights:  []model.Highlight{},
+	}
+	var months [13]int
+	var run model.Streak
+	for _, day := range days {
+		stats.Entries += day.Count
+		months[day.Date.Month()] += day.Count
+		if run.Days > 0 && day.Date.Equal(run.End.AddDate(0, 0, 1)) {
+			run.Days++
+			run.End = day.Date
+		} else {
+			run = model.Streak{Days: 1, Start: day.Date, End: day.Date}
+		}
+		if run.Days > stats.LongestStreak.Days {
+			stats.LongestStreak = run
+		}
+	}
+	for month := time.January; month <= time.December; month++ {
+		if months[month] > stats.BusiestMonthEntries {
+			stats.BusiestMonth = month
+			stats.BusiestMonthEntries = months[month]
+		}
+	}
+	// The highlight of each month is the photo of its longest diary.
+	var highlights [13]*model.Diary
+	for i, diary := range diaries {
+		stats.WordCount += len(strings.Fields(diary.Content))
+		length := utf8.RuneCountInString(diary.Content)
+		if length > stats.LongestEntryLength {
+			stats.LongestEntry = diary.ID
+			stats.LongestEntryDate = diary.Date
+			stats.LongestEntryLength = length
+		}
+		if diary.Image == "" {
+			continue
+		}
+		month := diary.Date.In(loc).Month()
+		if h := highlights[month]; h == nil || length > utf8.RuneCountInString(h.Content) {
+			highlights[month] = &diaries[i]
+		}
+	}
+	for _, diary := range highlights {
+		if diary != nil {
+			stats.Highlights = append(stats.Highlights, model.Highlight{
+				DiaryID: diary.ID,
+				Date:    diary.Date,
+				Image:   diary.Image,
+			})
+		}
+	}
+	return stats, nil
+}
+
+// topCounts returns the n names with the highest counts, highest first.
+// Names that count zero are left out.
+func topCounts(counts map[string]int, n int) []model.NameCount {
+	top := make([]model.NameCount, 0, len(counts))
+	for name, count := range counts {
+		if count > 0 {
+			top = append(top, model.NameCount{Name: name, Count: count})
+		}
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Name < top[j].Name
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// diariesChanged marks the reports of the years of dates as out of date and
+// requests them again.
+func (ds *DiaryService) diariesChanged(ctx context.Context, userID string, dates ...time.Time) error {
+	loc, err := ds.Location(ctx, userID)
+	if err != nil {
+		return err
+	}
+	years := make(map[int]bool, len(dates))
+	for _, date := range dates {
+		year := date.In(loc).Year()
+		if years[year] {
+			continue
+		}
+		years[year] = true
+		if err := ds.repo.Reports.MarkChanged(ctx, userID, year); err != nil {
+			return err
+		}
+		ds.requestReport(ReportRequest{UserID: userID, Year: year})
+	}
+	return nil
+}
+
+// requestReport queues a report for generation unless it is already queued
+// or the queue is full.
+func (ds *DiaryService) requestReport(req ReportRequest) {
+	ds.reportMu.Lock()
+	defer ds.reportMu.Unlock()
+	if ds.reportPending[req] {
+		return
+	}
+	select {
+	case ds.reportQueue <- req:
+		ds.reportPending[req] = true
+	default:
+	}
+}
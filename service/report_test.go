@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dailyscoop-backend/config"
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository/memory"
+)
+
+func TestYearlyReportRegeneratesOnChange(t *testing.T) {
+	ctx := context.Background()
+	loc, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := NewDiaryService(memory.New(config.MemoryConfig{}), loc)
+	for _, day := range []int{5, 6, 7} {
+		if _, err := ds.WriteDiary(ctx, model.Diary{
+			UserID:   "user",
+			Content:  "비가 왔다 rainy day",
+			Image:    "image",
+			Date:     time.Date(2026, 1, day, 0, 0, 0, 0, loc),
+			Emotions: []string{"슬픔"},
+			Theme:    "rain",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	generate := func() {
+		for {
+			select {
+			case req := <-ds.ReportRequests():
+				if err := ds.GenerateYearlyReport(ctx, req); err != nil {
+					t.Fatal(err)
+				}
+			default:
+				return
+			}
+		}
+	}
+
+	generate()
+	report, err := ds.YearlyReport(ctx, "user", 2026)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Fresh() {
+		t.Fatal("report is not fresh after generation")
+	}
+	stats := report.Stats
+	if stats.Entries != 3 || stats.LongestStreak.Days != 3 || stats.WordCount != 12 || stats.BusiestMonth != time.January {
+		t.Errorf("stats = %+v", stats)
+	}
+	if len(stats.TopEmotions) != 1 || stats.TopEmotions[0] != (model.NameCount{Name: "슬픔", Count: 3}) {
+		t.Errorf("top emotions = %v", stats.TopEmotions)
+	}
+	if len(stats.Highlights) != 1 {
+		t.Errorf("highlights = %v, want one for January", stats.Highlights)
+	}
+
+	if err := ds.DeleteDiary(ctx, "user", time.Date(2026, 1, 6, 0, 0, 0, 0, loc)); err != nil {
+		t.Fatal(err)
+	}
+	report, err = ds.YearlyReport(ctx, "user", 2026)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Fresh() {
+		t.Error("report is fresh after a diary was deleted")
+	}
+	generate()
+	report, err = ds.YearlyReport(ctx, "user", 2026)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Fresh() || report.Stats.Entries != 2 || report.Stats.LongestStreak.Days != 1 {
+		t.Errorf("regenerated report = %+v", report)
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"dailyscoop-backend/diff"
+	"dailyscoop-backend/model"
+)
+
+func (ds *DiaryService) Revisions(ctx context.Context, userID string, diaryID string) ([]model.DiaryRevision, error) {
+	if _, err := ds.repo.Diaries.FindByID(ctx, userID, diaryID); err != nil {
+		return nil, err
+	}
+	return ds.repo.Revisions.FindByDiaryID(ctx, userID, diaryID)
+}
+
+func (ds *DiaryService) Revision(ctx context.Context, userID string, diaryID string, id string) (model.DiaryRevision, error) {
+	if _, err := ds.repo.Diaries.FindByID(ctx, userID, diaryID); err != nil {
+		return model.DiaryRevision{}, err
+	}
+	return ds.repo.Revisions.FindByID(ctx, userID, diaryID, id)
+}
+
+// RevisionDiff describes how a diary changed between two revisions. Content
+// is compared line by line, the other fields as a whole.
+type RevisionDiff struct {
+	From            model.DiaryRevision
+	To              model.DiaryRevision
+	Lines           []diff.Line
+	ImageChanged    bool
+	EmotionsChanged bool
+	ThemeChanged    bool
+}
+
+// DiffRevisions compares two revisions of a diary. An empty toID compares
+// against the latest revision.
+func (ds *DiaryService) DiffRevisions(ctx context.Context, userID string, diaryID string, fromID string, toID string) (RevisionDiff, error) {
+	if _, err := ds.repo.Diaries.FindByID(ctx, userID, diaryID); err != nil {
+		return RevisionDiff{}, err
+	}
+	from, err := ds.repo.Revisions.FindByID(ctx, userID, diaryID, fromID)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	var to model.DiaryRevision
+	if toID == "" {
+		revisions, err := ds.repo.Revisions.FindByDiaryID(ctx, userID, diaryID)
+		if err != nil {
+			return RevisionDiff{}, err
+		}
+		to = revisions[len(revisions)-1]
+	} else {
+		to, err = ds.repo.Revisions.FindByID(ctx, userID, diaryID, toID)
+		if err != nil {
+			return RevisionDiff{}, err
+		}
+	}
+	return RevisionDiff{
+		From:            from,
+		To:              to,
+		Lines:           diff.Lines(from.Content, to.Content),
+		ImageChanged:    from.Image != to.Image,
+		EmotionsChanged: !equalStrings(from.Emotions, to.Emotions),
+		ThemeChanged:    from.Theme != to.Theme,
+	}, nil
+}
+
+// RestoreRevision writes the content of an older revision back to the diary.
+// The diary keeps its date, and the restore is recorded as a new revision.
+func (ds *DiaryService) RestoreRevision(ctx context.Context, userID string, diaryID string, id string) (model.Diary, error) {
+	revision, err := ds.repo.Revisions.FindByID(ctx, userID, diaryID, id)
+	if err != nil {
+		return model.Diary{}, err
+	}
+	diary, err := ds.repo.Diaries.FindByID(ctx, userID, diaryID)
+	if err != nil {
+		return model.Diary{}, err
+	}
+	diary.Content = revision.Content
+	diary.Image = revision.Image
+	diary.Emotions = revision.Emotions
+	diary.Theme = revision.Theme
+	return ds.UpdateDiary(ctx, diary)
+}
+
+func (ds *DiaryService) recordRevision(ctx context.Context, diary model.Diary) error {
+	return ds.insertRevision(ctx, diary, time.Now())
+}
+
+// ensureBaseRevision keeps the state of a diary written before revisions were
+// recorded, so that the first edit does not lose it. Its time is unknown, so
+// the diary date stands in for it.
+func (ds *DiaryService) ensureBaseRevision(ctx context.Context, diary model.Diary) error {
+	count, err := ds.repo.Revisions.CountByDiaryID(ctx, diary.UserID, diary.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return ds.insertRevision(ctx, diary, diary.Date)
+}
+
+func (ds *DiaryService) insertRevision(ctx context.Context, diary model.Diary, createdAt time.Time) error {
+	return ds.repo.Revisions.Insert(ctx, model.DiaryRevision{
+		ID:        uuid.NewV4().String(),
+		DiaryID:   diary.ID,
+		UserID:    diary.UserID,
+		Content:   diary.Content,
+		Image:     diary.Image,
+		Emotions:  diary.Emotions,
+		Theme:     diary.Theme,
+		CreatedAt: createdAt,
+	})
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+	"dailyscoop-backend/search"
+)
+
+// SearchMatch is how well a diary matches a search query, and the part of
+// its content that matched.
+type SearchMatch struct {
+	Score   float64
+	Snippet search.Snippet
+}
+
+// searchByRelevance ranks every diary that contains the query and returns a
+// page of them, best first. The cursor is the offset of the page. Diaries are
+// ranked by their stored term counts, so only the page is loaded in full.
+func (ds *DiaryService) searchByRelevance(ctx context.Context, query repository.DiaryQuery, opts DiaryListOptions) (DiaryPage, error) {
+	offset := 0
+	if opts.Cursor != "" {
+		var err error
+		offset, err = decodeOffsetCursor(opts.Cursor)
+		if err != nil {
+			return DiaryPage{}, err
+		}
+	}
+	query.Sort = -1
+	candidates, err := ds.repo.Diaries.FindMatches(ctx, query, opts.Search)
+	if err != nil {
+		return DiaryPage{}, err
+	}
+	docs := make([]search.Document, 0, len(candidates))
+	for _, candidate := range candidates {
+		docs = append(docs, search.Document{
+			ID:     candidate.ID,
+			Counts: candidate.TermCounts,
+			Length: candidate.TermLength,
+			Phrase: candidate.Phrase,
+		})
+	}
+	results := search.Rank(opts.Search, docs)
+	if offset > len(results) {
+		offset = len(results)
+	}
+	end := len(results)
+	if opts.Limit > 0 && offset+opts.Limit < end {
+		end = offset + opts.Limit
+	}
+	var page DiaryPage
+	if end < len(results) {
+		page.NextCursor = encodeOffsetCursor(end)
+	}
+	if offset == end {
+		return page, nil
+	}
+	ids := make([]string, 0, end-offset)
+	for _, result := range results[offset:end] {
+		ids = append(ids, result.ID)
+	}
+	diaries, err := ds.repo.Diaries.Find(ctx, repository.DiaryQuery{
+		UserID: query.UserID,
+		IDs:    ids,
+	})
+	if err != nil {
+		return DiaryPage{}, err
+	}
+	byID := make(map[string]model.Diary, len(diaries))
+	for _, diary := range diaries {
+		byID[diary.ID] = diary
+	}
+	for _, result := range results[offset:end] {
+		diary, ok := byID[result.ID]
+		if !ok {
+			// Trashed since it was ranked.
+			continue
+		}
+		page.Diaries = append(page.Diaries, diary)
+		page.Matches = append(page.Matches, SearchMatch{
+			Score:   result.Score,
+			Snippet: search.MakeSnippet(diary.Content, opts.Search),
+		})
+	}
+	return page, nil
+}
+
+// indexDiary sets the search terms of the diary from its content.
+func indexDiary(diary *model.Diary) {
+	diary.Terms = search.Terms(diary.Content)
+	diary.TermCounts, diary.TermLength = search.Counts(diary.Content)
+	diary.TermsVersion = search.Version
+}
+
+// matchDiaries scores diaries listed in date order against query.
+func matchDiaries(query string, diaries []model.Diary) []SearchMatch {
+	docs := make([]search.Document, 0, len(diaries))
+	for _, diary := range diaries {
+		docs = append(docs, search.NewDocument(diary.ID, diary.Content, query))
+	}
+	scores := make(map[string]float64, len(diaries))
+	for _, result := range search.Rank(query, docs) {
+		scores[result.ID] = result.Score
+	}
+	matches := make([]SearchMatch, 0, len(diaries))
+	for _, diary := range diaries {
+		matches = append(matches, SearchMatch{
+			Score:   scores[diary.ID],
+			Snippet: search.MakeSnippet(diary.Content, query),
+		})
+	}
+	return matches
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"dailyscoop-backend/config"
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository/memory"
+)
+
+func TestSearchByRelevancePages(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New(config.MemoryConfig{})
+	us := NewUserService(repo, time.Hour)
+	ds := NewDiaryService(repo, time.UTC)
+	if err := us.RegisterUser(ctx, model.User{ID: "u1", Nickname: "user", MultipleEntries: true}); err != nil {
+		t.Fatal(err)
+	}
+	contents := []string{
+		"행복",
+		"행복 행복 행복",
+		"오늘은 비",
+		"행복 행복",
+		"행복한 하루 그리고 아주 길고 긴 이야기",
+	}
+	ids := make(map[string]string)
+	for i, content := range contents {
+		diary, err := ds.WriteDiary(ctx, model.Diary{
+			UserID:  "u1",
+			Content: content,
+			Date:    time.Date(2026, 3, i+1, 0, 0, 0, 0, time.UTC),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[diary.ID] = content
+	}
+
+	var got []string
+	opts := DiaryListOptions{Search: "행복", Limit: 2}
+	for pages := 0; ; pages++ {
+		if pages > len(contents) {
+			t.Fatal("too many pages")
+		}
+		page, err := ds.ListDiaries(ctx, "u1", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Matches) != len(page.Diaries) {
+			t.Fatalf("got %d matches for %d diaries", len(page.Matches), len(page.Diaries))
+		}
+		for i, diary := range page.Diaries {
+			got = append(got, ids[diary.ID])
+			if diary.Content == "" || len(page.Matches[i].Snippet.Highlights) == 0 {
+				t.Errorf("diary %q was not loaded with its snippet", ids[diary.ID])
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	want := []string{
+		"행복 행복 행복",
+		"행복 행복",
+		"행복",
+		"행복한 하루 그리고 아주 길고 긴 이야기",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+// seenInterval is how often the last use of a session is recorded. Requests
+// in between do not write to the database.
+const seenInterval = time.Minute
+
+var (
+	ErrInvalidRefreshToken = errors.New("service: invalid refresh token")
+	// ErrRefreshTokenReused means a refresh token was used after it had
+	// been rotated, so it may have been stolen. The session is ended.
+	ErrRefreshTokenReused = errors.New("service: refresh token reused")
+)
+
+// CreateSession signs the user in on a device and returns the session with
+// its refresh token.
+func (us *UserService) CreateSession(ctx context.Context, userID string, device string) (model.Session, string, error) {
+	secret, err := newTokenSecret()
+	if err != nil {
+		return model.Session{}, "", err
+	}
+	now := time.Now()
+	session := model.Session{
+		ID:         uuid.NewV4().String(),
+		UserID:     userID,
+		TokenHash:  hashTokenSecret(secret),
+		Device:     device,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(us.sessionTTL),
+	}
+	if err := us.repo.Sessions.Insert(ctx, session); err != nil {
+		return model.Session{}, "", err
+	}
+	return session, session.ID + "." + secret, nil
+}
+
+// RefreshSession exchanges a refresh token for a new one and extends the
+// session. Using a token that was already exchanged ends the session and
+// fails with ErrRefreshTokenReused, since either the device or whoever took
+// the token from it holds a newer one.
+func (us *UserService) RefreshSession(ctx context.Context, token string) (model.Session, string, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return model.Session{}, "", ErrInvalidRefreshToken
+	}
+	id, secret := parts[0], parts[1]
+	session, err := us.repo.Sessions.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.Session{}, "", ErrInvalidRefreshToken
+		}
+		return model.Session{}, "", err
+	}
+	now := time.Now()
+	if session.Expired(now) {
+		return model.Session{}, "", ErrInvalidRefreshToken
+	}
+	newSecret, err := newTokenSecret()
+	if err != nil {
+		return model.Session{}, "", err
+	}
+	oldHash := hashTokenSecret(secret)
+	if oldHash != session.TokenHash {
+		return model.Session{}, "", us.endReusedSession(ctx, session)
+	}
+	session.TokenHash = hashTokenSecret(newSecret)
+	session.LastSeenAt = now
+	session.ExpiresAt = now.Add(us.sessionTTL)
+	if err := us.repo.Sessions.Rotate(ctx, session.ID, oldHash, session.TokenHash, session.LastSeenAt, session.ExpiresAt); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// The token was exchanged by a concurrent request.
+			return model.Session{}, "", us.endReusedSession(ctx, session)
+		}
+		return model.Session{}, "", err
+	}
+	return session, session.ID + "." + newSecret, nil
+}
+
+func (us *UserService) endReusedSession(ctx context.Context, session model.Session) error {
+	if err := us.repo.Sessions.Delete(ctx, session.UserID, session.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Sessions returns the sessions of the user that have not expired, most
+// recently seen first.
+func (us *UserService) Sessions(ctx context.Context, userID string) ([]model.Session, error) {
+	sessions, err := us.repo.Sessions.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	active := sessions[:0]
+	for _, session := range sessions {
+		if !session.Expired(now) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// SessionActive reports whether the session of the user exists and has not
+// expired, and records that it was used.
+func (us *UserService) SessionActive(ctx context.Context, userID string, id string) (bool, error) {
+	session, err := us.repo.Sessions.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	now := time.Now()
+	if session.UserID != userID || session.Expired(now) {
+		return false, nil
+	}
+	if now.Sub(session.LastSeenAt) >= seenInterval {
+		if err := us.repo.Sessions.Touch(ctx, id, now); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// DeleteSession signs the user out of a device.
+func (us *UserService) DeleteSession(ctx context.Context, userID string, id string) error {
+	return us.repo.Sessions.Delete(ctx, userID, id)
+}
+
+func newTokenSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
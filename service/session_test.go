@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"dailyscoop-backend/config"
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository/memory"
+)
+
+func TestRefreshSessionRotates(t *testing.T) {
+	ctx := context.Background()
+	us := NewUserService(memory.New(config.MemoryConfig{}), time.Hour)
+	session, first, err := us.CreateSession(ctx, "user", "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshed, second, err := us.RefreshSession(ctx, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.ID != session.ID {
+		t.Errorf("refreshed session %q, want %q", refreshed.ID, session.ID)
+	}
+	if second == first {
+		t.Error("refresh token was not rotated")
+	}
+	if _, third, err := us.RefreshSession(ctx, second); err != nil {
+		t.Fatal(err)
+	} else {
+		second = third
+	}
+
+	// Using the first token again ends the session, so the latest token no
+	// longer works either.
+	if _, _, err := us.RefreshSession(ctx, first); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("reusing a token: got %v, want ErrRefreshTokenReused", err)
+	}
+	if _, _, err := us.RefreshSession(ctx, second); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refreshing an ended session: got %v, want ErrInvalidRefreshToken", err)
+	}
+	sessions, err := us.Sessions(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("got %d sessions after reuse, want 0", len(sessions))
+	}
+}
+
+func TestRefreshSessionRejectsInvalidTokens(t *testing.T) {
+	ctx := context.Background()
+	us := NewUserService(memory.New(config.MemoryConfig{}), time.Hour)
+	for _, token := range []string{"", "no-separator", "unknown.secret"} {
+		if _, _, err := us.RefreshSession(ctx, token); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("RefreshSession(%q): got %v, want ErrInvalidRefreshToken", token, err)
+		}
+	}
+
+	expired := NewUserService(memory.New(config.MemoryConfig{}), -time.Second)
+	_, token, err := expired.CreateSession(ctx, "user", "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := expired.RefreshSession(ctx, token); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refreshing an expired session: got %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestUpdatePasswordRevokesTokens(t *testing.T) {
+	ctx := context.Background()
+	us := NewUserService(memory.New(config.MemoryConfig{}), time.Hour)
+	if err := us.RegisterUser(ctx, model.User{ID: "user", Password: "old", Nickname: "user"}); err != nil {
+		t.Fatal(err)
+	}
+	session, token, err := us.CreateSession(ctx, "user", "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := us.UpdatePassword(ctx, "user", "new"); err != nil {
+		t.Fatal(err)
+	}
+	user, err := us.UserByID(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.TokenVersion != 1 {
+		t.Errorf("got token version %d, want 1", user.TokenVersion)
+	}
+	active, err := us.SessionActive(ctx, "user", session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active {
+		t.Error("session is still active after the password changed")
+	}
+	if _, _, err := us.RefreshSession(ctx, token); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refreshing after the password changed: got %v, want ErrInvalidRefreshToken", err)
+	}
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"dailyscoop-backend/period"
+)
+
+// Streak is a run of consecutive days with at least one diary. Start and End
+// are the first and the last day of the run, and are zero when Days is zero.
+type Streak struct {
+	Days  int
+	Start time.Time
+	End   time.Time
+}
+
+type StreakStats struct {
+	// Current is the streak that ends today, or yesterday when nothing has
+	// been written yet today.
+	Current        Streak
+	Longest        Streak
+	WrittenToday   bool
+	EntriesPerWeek float64
+}
+
+// Streaks computes the writing streaks of the user as of now, with days in
+// the time zone of the user.
+func (ds *DiaryService) Streaks(ctx context.Context, userID string, now time.Time) (StreakStats, error) {
+	loc, err := ds.Location(ctx, userID)
+	if err != nil {
+		return StreakStats{}, err
+	}
+	today := startOfDay(now, loc)
+	days, err := ds.repo.Stats.CountByDay(ctx, userID, time.Time{}, today.AddDate(0, 0, 1), loc)
+	if err != nil {
+		return StreakStats{}, err
+	}
+	var stats StreakStats
+	var run Streak
+	entries := 0
+	for _, day := range days {
+		entries += day.Count
+		if run.Days > 0 && day.Date.Equal(run.End.AddDate(0, 0, 1)) {
+			run.Days++
+			run.End = day.Date
+		} else {
+			run = Streak{Days: 1, Start: day.Date, End: day.Date}
+		}
+		if run.Days >= stats.Longest.Days {
+			stats.Longest = run
+		}
+	}
+	stats.WrittenToday = run.Days > 0 && run.End.Equal(today)
+	if stats.WrittenToday || (run.Days > 0 && run.End.Equal(today.AddDate(0, 0, -1))) {
+		stats.Current = run
+	}
+	if entries > 0 {
+		span := math.Round(today.Sub(days[0].Date).Hours()/24) + 1
+		if span < 7 {
+			span = 7
+		}
+		stats.EntriesPerWeek = float64(entries) / (span / 7)
+	}
+	return stats, nil
+}
+
+// EmotionTrend is the number of diaries with each emotion in consecutive
+// periods. MovingAverages is only set when a window was asked for.
+type EmotionTrend struct {
+	Periods        []period.Period
+	Counts         map[string][]int
+	MovingAverages map[string][]float64
+}
+
+// EmotionTrend splits p into periods of unit and counts the emotions in each
+// of them. A window above one adds the trailing moving average over that many
+// periods.
+func (ds *DiaryService) EmotionTrend(ctx context.Context, userID string, p period.Period, unit period.Unit, weekStart time.Weekday, window int) (EmotionTrend, error) {
+	periods, err := p.Split(unit, weekStart)
+	if err != nil {
+		return EmotionTrend{}, err
+	}
+	loc, err := ds.Location(ctx, userID)
+	if err != nil {
+		return EmotionTrend{}, err
+	}
+	counts, err := ds.repo.Stats.CountEmotionsByPeriod(ctx, userID, p.Start, p.End, unit, weekStart, loc)
+	if err != nil {
+		return EmotionTrend{}, err
+	}
+	all, err := ds.activeEmotions(ctx, userID)
+	if err != nil {
+		return EmotionTrend{}, err
+	}
+	trend := EmotionTrend{
+		Periods: periods,
+		Counts:  make(map[string][]int),
+	}
+	for _, emotion := range all {
+		trend.Counts[emotion.Name] = make([]int, len(periods))
+	}
+	// The periods of the counts start where whole periods of unit start,
+	// which is before p.Start for the first one.
+	index := make(map[int64]int, len(periods))
+	for i, q := range periods {
+		whole, err := period.Of(unit, q.Start, weekStart)
+		if err != nil {
+			return EmotionTrend{}, err
+		}
+		index[whole.Start.Unix()] = i
+	}
+	for _, count := range counts {
+		i, ok := index[count.Start.Unix()]
+		if !ok {
+			continue
+		}
+		if trend.Counts[count.Emotion] == nil {
+			trend.Counts[count.Emotion] = make([]int, len(periods))
+		}
+		trend.Counts[count.Emotion][i] += count.Count
+	}
+	if window > 1 {
+		trend.MovingAverages = make(map[string][]float64, len(trend.Counts))
+		for emotion, series := range trend.Counts {
+			trend.MovingAverages[emotion] = movingAverage(series, window)
+		}
+	}
+	return trend, nil
+}
+
+// movingAverage averages each value with the ones before it in the window.
+// The first values average over the fewer values there are.
+func movingAverage(series []int, window int) []float64 {
+	averages := make([]float64, len(series))
+	sum := 0
+	for i, v := range series {
+		sum += v
+		if i >= window {
+			sum -= series[i-window]
+		}
+		n := window
+		if i+1 < window {
+			n = i + 1
+		}
+		averages[i] = float64(sum) / float64(n)
+	}
+	return averages
+}
+
+// CountThemes counts the diaries with each theme in the period. Themes of the
+// catalog that were not used count zero.
+func (ds *DiaryService) CountThemes(ctx context.Context, userID string, p period.Period) (map[string]int, error) {
+	themes, err := ds.repo.Stats.CountThemes(ctx, userID, p.Start, p.End)
+	if err != nil {
+		return nil, err
+	}
+	all, err := ds.repo.Themes.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, theme := range all {
+		if _, ok := themes[theme.Name]; !ok {
+			themes[theme.Name] = 0
+		}
+	}
+	return themes, nil
+}
+
+// EmotionMatrix is the co-occurrence of emotions. Counts[i][j] is the number
+// of diaries with both Emotions[i] and Emotions[j], and Counts[i][i] the
+// number of diaries with Emotions[i].
+type EmotionMatrix struct {
+	Emotions []string
+	Counts   [][]int
+}
+
+// EmotionMatrix counts which emotions were felt together in the period. The
+// emotions of the catalog come first in catalog order, followed by any other
+// emotion found in the diaries.
+func (ds *DiaryService) EmotionMatrix(ctx context.Context, userID string, p period.Period) (EmotionMatrix, error) {
+	emotions, err := ds.CountEmotions(ctx, userID, p)
+	if err != nil {
+		return EmotionMatrix{}, err
+	}
+	pairs, err := ds.repo.Stats.CountEmotionPairs(ctx, userID, p.Start, p.End)
+	if err != nil {
+		return EmotionMatrix{}, err
+	}
+	names, err := ds.emotionNames(ctx, userID, emotions)
+	if err != nil {
+		return EmotionMatrix{}, err
+	}
+	index := make(map[string]int, len(names))
+	matrix := EmotionMatrix{
+		Emotions: names,
+		Counts:   make([][]int, len(names)),
+	}
+	for i, name := range names {
+		index[name] = i
+		matrix.Counts[i] = make([]int, len(names))
+		matrix.Counts[i][i] = emotions[name]
+	}
+	for _, pair := range pairs {
+		i, ok := index[pair.A]
+		if !ok {
+			continue
+		}
+		j, ok := index[pair.B]
+		if !ok {
+			continue
+		}
+		matrix.Counts[i][j] = pair.Count
+		matrix.Counts[j][i] = pair.Count
+	}
+	return matrix, nil
+}
+
+// EmotionsByWeekday counts the emotions of the diaries in the period by the
+// day of the week they were written on in the time zone of the user.
+func (ds *DiaryService) EmotionsByWeekday(ctx context.Context, userID string, p period.Period) ([7]map[string]int, error) {
+	var weekdays [7]map[string]int
+	loc, err := ds.Location(ctx, userID)
+	if err != nil {
+		return weekdays, err
+	}
+	counts, err := ds.repo.Stats.CountEmotionsByWeekday(ctx, userID, p.Start, p.End, loc)
+	if err != nil {
+		return weekdays, err
+	}
+	names, err := ds.emotionNames(ctx, userID, nil)
+	if err != nil {
+		return weekdays, err
+	}
+	for i := range weekdays {
+		weekdays[i] = make(map[string]int, len(names))
+		for _, name := range names {
+			weekdays[i][name] = 0
+		}
+	}
+	for _, count := range counts {
+		weekdays[count.Weekday][count.Emotion] += count.Count
+	}
+	return weekdays, nil
+}
+
+// EmotionsByTheme counts the emotions of the diaries in the period by their
+// theme. Every theme of the catalog is present.
+func (ds *DiaryService) EmotionsByTheme(ctx context.Context, userID string, p period.Period) (map[string]map[string]int, error) {
+	counts, err := ds.repo.Stats.CountEmotionsByTheme(ctx, userID, p.Start, p.End)
+	if err != nil {
+		return nil, err
+	}
+	names, err := ds.emotionNames(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	themes, err := ds.repo.Themes.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byTheme := make(map[string]map[string]int, len(themes))
+	newCounts := func() map[string]int {
+		m := make(map[string]int, len(names))
+		for _, name := range names {
+			m[name] = 0
+		}
+		return m
+	}
+	for _, theme := range themes {
+		byTheme[theme.Name] = newCounts()
+	}
+	for _, count := range counts {
+		if byTheme[count.Theme] == nil {
+			byTheme[count.Theme] = newCounts()
+		}
+		byTheme[count.Theme][count.Emotion] += count.Count
+	}
+	return byTheme, nil
+}
+
+// emotionNames returns the names of the active emotions of the catalog in
+// display order and the custom emotions of the user, followed by the other
+// names in counts in alphabetical order.
+func (ds *DiaryService) emotionNames(ctx context.Context, userID string, counts map[string]int) ([]string, error) {
+	all, err := ds.activeEmotions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(all))
+	seen := make(map[string]bool, len(all))
+	for _, emotion := range all {
+		names = append(names, emotion.Name)
+		seen[emotion.Name] = true
+	}
+	var others []string
+	for name := range counts {
+		if !seen[name] {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...), nil
+}
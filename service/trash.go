@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
+)
+
+func (ds *DiaryService) TrashedDiaries(ctx context.Context, userID string) ([]model.Diary, error) {
+	return ds.repo.Diaries.FindTrashed(ctx, userID)
+}
+
+// RestoreDiary takes a diary out of the trash. Unless the user keeps multiple
+// entries per day, it fails with ErrDiaryExists when another diary has been
+// written on the same day in the meantime.
+func (ds *DiaryService) RestoreDiary(ctx context.Context, userID string, id string) (model.Diary, error) {
+	diary, err := ds.repo.Diaries.FindTrashedByID(ctx, userID, id)
+	if err != nil {
+		return model.Diary{}, err
+	}
+	multipleEntries, err := ds.multipleEntries(ctx, userID)
+	if err != nil {
+		return model.Diary{}, err
+	}
+	if !multipleEntries {
+		loc, err := ds.Location(ctx, userID)
+		if err != nil {
+			return model.Diary{}, err
+		}
+		date := startOfDay(diary.Date, loc)
+		_, err = ds.repo.Diaries.FindOneInRange(ctx, userID, date, date.AddDate(0, 0, 1))
+		if err == nil {
+			return model.Diary{}, ErrDiaryExists
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return model.Diary{}, err
+		}
+	}
+	if err := ds.repo.Diaries.Restore(ctx, userID, id); err != nil {
+		return model.Diary{}, err
+	}
+	if err := ds.diariesChanged(ctx, userID, diary.Date); err != nil {
+		return model.Diary{}, err
+	}
+	diary.DeletedAt = nil
+	return diary, nil
+}
+
+// DeleteTrashedDiary removes a diary in the trash for good, together with its
+// revisions.
+func (ds *DiaryService) DeleteTrashedDiary(ctx context.Context, userID string, id string) error {
+	if err := ds.repo.Diaries.DeleteTrashed(ctx, userID, id); err != nil {
+		return err
+	}
+	return ds.repo.Revisions.DeleteByDiaryID(ctx, userID, id)
+}
+
+// PurgeTrash removes the diaries of every user that were trashed before the
+// given time, and returns how many were removed.
+func (ds *DiaryService) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	diaries, err := ds.repo.Diaries.PurgeTrashed(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	for _, diary := range diaries {
+		if err := ds.repo.Revisions.DeleteByDiaryID(ctx, diary.UserID, diary.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(diaries), nil
+}
@@ -2,47 +2,39 @@ package service
 
 import (
 	"context"
+	"errors"
+	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 
-	"dailyscoop-backend/config"
 	"dailyscoop-backend/model"
+	"dailyscoop-backend/repository"
 )
 
+var ErrInvalidTimezone = errors.New("service: invalid time zone")
+
 type UserService struct {
-	cfg config.MongoConfig
-	mc  *mongo.Client
+	repo repository.Repository
+	// sessionTTL is how long a session lasts without being refreshed.
+	sessionTTL time.Duration
 }
 
-func NewUserService(cfg config.MongoConfig, mc *mongo.Client) *UserService {
+func NewUserService(repo repository.Repository, sessionTTL time.Duration) *UserService {
 	return &UserService{
-		cfg: cfg,
-		mc:  mc,
+		repo:       repo,
+		sessionTTL: sessionTTL,
 	}
 }
 
 func (us *UserService) UserByID(ctx context.Context, id string) (model.User, error) {
-	coll := us.mc.Database(us.cfg.Database).Collection("users")
-	var user model.User
-	if err := coll.FindOne(ctx, bson.M{model.UserIDKey: id}).Decode(&user); err != nil {
-		return model.User{}, err
-	}
-	return user, nil
+	return us.repo.Users.FindByID(ctx, id)
 }
 
 func (us *UserService) UserByNickname(ctx context.Context, nickname string) (model.User, error) {
-	coll := us.mc.Database(us.cfg.Database).Collection("users")
-	var user model.User
-	if err := coll.FindOne(ctx, bson.M{model.UserNicknameKey: nickname}).Decode(&user); err != nil {
-		return model.User{}, err
-	}
-	return user, nil
+	return us.repo.Users.FindByNickname(ctx, nickname)
 }
 
 func (us *UserService) RegisterUser(ctx context.Context, user model.User) error {
-	coll := us.mc.Database(us.cfg.Database).Collection("users")
 	if user.Password != "" {
 		h, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 		if err != nil {
@@ -50,76 +42,90 @@ func (us *UserService) RegisterUser(ctx context.Context, user model.User) error
 		}
 		user.Password = string(h)
 	}
-	if _, err := coll.InsertOne(ctx, user); err != nil {
-		return err
-	}
-	return nil
+	user.CreatedAt = time.Now()
+	return us.repo.Users.Insert(ctx, user)
 }
 
 func (us *UserService) DeleteUser(ctx context.Context, userID string) error {
-	coll := us.mc.Database(us.cfg.Database).Collection("users")
-	if _, err := coll.DeleteOne(ctx, bson.M{
-		model.UserIDKey: userID,
-	}); err != nil {
+	if err := us.repo.Users.Delete(ctx, userID); err != nil {
+		return err
+	}
+	if err := us.repo.Diaries.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+	if err := us.repo.Revisions.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+	if err := us.repo.Sessions.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+	if err := us.repo.Favorites.DeleteByUserID(ctx, userID); err != nil {
 		return err
 	}
-	coll = us.mc.Database(us.cfg.Database).Collection("diaries")
-	if _, err := coll.DeleteMany(ctx, bson.M{
-		model.DiaryUserIDKey: userID,
-	}); err != nil {
+	if err := us.repo.CustomEmotions.DeleteByUserID(ctx, userID); err != nil {
 		return err
 	}
-	coll = us.mc.Database(us.cfg.Database).Collection("favorites")
-	if _, err := coll.DeleteMany(ctx, bson.M{
-		model.FavoriteUserIDKey: userID,
-	}); err != nil {
+	if err := us.repo.Reports.DeleteByUserID(ctx, userID); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (us *UserService) UpdateNickname(ctx context.Context, userID string, newNickname string) error {
-	coll := us.mc.Database(us.cfg.Database).Collection("users")
-	if _, err := coll.UpdateOne(ctx, bson.M{
-		model.UserIDKey: userID,
-	}, bson.M{
-		"$set": bson.M{
-			model.UserNicknameKey: newNickname,
-		},
-	}); err != nil {
-		return err
-	}
-	return nil
+	return us.repo.Users.UpdateNickname(ctx, userID, newNickname)
 }
 
 func (us *UserService) UpdatePassword(ctx context.Context, userID string, newPassword string) error {
-	coll := us.mc.Database(us.cfg.Database).Collection("users")
 	h, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
-	if _, err := coll.UpdateOne(ctx, bson.M{
-		model.UserIDKey: userID,
-	}, bson.M{
-		"$set": bson.M{
-			model.UserPasswordKey: h,
-		},
-	}); err != nil {
+	if err := us.repo.Users.UpdatePassword(ctx, userID, string(h)); err != nil {
 		return err
 	}
-	return nil
+	return us.RevokeTokens(ctx, userID)
 }
 
-func (us *UserService) UpdateProfileImage(ctx context.Context, userID string, image string) error {
-	coll := us.mc.Database(us.cfg.Database).Collection("users")
-	if _, err := coll.UpdateOne(ctx, bson.M{
-		model.UserIDKey: userID,
-	}, bson.M{
-		"$set": bson.M{
-			model.UserProfileImageKey: image,
-		},
-	}); err != nil {
+// RevokeTokens signs the user out everywhere. Access tokens issued before stop
+// working at once, and every session ends.
+func (us *UserService) RevokeTokens(ctx context.Context, userID string) error {
+	if err := us.repo.Users.IncrementTokenVersion(ctx, userID); err != nil {
 		return err
 	}
-	return nil
+	return us.repo.Sessions.DeleteByUserID(ctx, userID)
+}
+
+func (us *UserService) UpdateMultipleEntries(ctx context.Context, userID string, multipleEntries bool) error {
+	return us.repo.Users.UpdateMultipleEntries(ctx, userID, multipleEntries)
+}
+
+// UpdateTimezone sets the IANA time zone of the user, such as "Asia/Seoul".
+func (us *UserService) UpdateTimezone(ctx context.Context, userID string, timezone string) error {
+	if _, err := LoadLocation(timezone); err != nil {
+		return err
+	}
+	if err := us.repo.Users.UpdateTimezone(ctx, userID, timezone); err != nil {
+		return err
+	}
+	// Diaries written near midnight on New Year's Eve may now fall in
+	// another year.
+	return us.repo.Reports.MarkAllChanged(ctx, userID)
+}
+
+// LoadLocation loads an IANA time zone. It rejects the empty name and
+// "Local", which depend on the machine and are not understood by the
+// database.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+func (us *UserService) UpdateProfileImage(ctx context.Context, userID string, image string) error {
+	return us.repo.Users.UpdateProfileImage(ctx, userID, image)
 }